@@ -9,6 +9,7 @@ type Channel[T any] struct {
 	channel      <-chan T
 	unsubscriber func()
 	toNode       pipelineNode
+	graphToNode  pipelineNode // shown as reading from the Channel in the pipeline graph, without subscribing to it
 	mutex        sync.Mutex
 }
 
@@ -36,9 +37,32 @@ func (c *Channel[T]) setToNode(toNode pipelineNode) {
 }
 
 func (c *Channel[T]) getToNode() pipelineNode {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	return c.toNode
 }
 
+// setGraphToNode makes the pipeline graph show the node as reading from the Channel, e.g. a FlatMap reading its inner channels.
+// Unlike setToNode, it doesn't subscribe the node, so it doesn't affect how the pipeline runs.
+func (c *Channel[T]) setGraphToNode(toNode pipelineNode) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.graphToNode = toNode
+}
+
+// getGraphToNode returns the node reading from the Channel as shown in the pipeline graph
+func (c *Channel[T]) getGraphToNode() pipelineNode {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.toNode != nil {
+		return c.toNode
+	}
+	return c.graphToNode
+}
+
 func (c *Channel[T]) getPipeline() *Pipeline {
 	return c.pipeline
 }
@@ -0,0 +1,191 @@
+package jpipe
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// A csvMapper maps CSV records to structs of type T, and back
+type csvMapper[T any] struct {
+	columns []int    // the index of the struct field for every column, or -1 if the column is ignored
+	names   []string // the name of every column, for error messages
+}
+
+// newCSVMapper returns a mapper matching columns to fields by position, or by name if there is a header.
+// supported checks whether fields of a type can be mapped.
+func newCSVMapper[T any](header []string, supported func(reflect.Type) bool) (*csvMapper[T], error) {
+	structType := reflect.TypeOf((*T)(nil)).Elem()
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("CSV records can only be mapped to structs, not %v", structType)
+	}
+
+	fields, names := []int{}, []string{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name := field.Tag.Get("csv")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if !supported(field.Type) {
+			return nil, fmt.Errorf("field %s of %v has unsupported type %v for CSV records", field.Name, structType, field.Type)
+		}
+		fields = append(fields, i)
+		names = append(names, name)
+	}
+
+	if header == nil {
+		return &csvMapper[T]{columns: fields, names: names}, nil
+	}
+
+	mapper := &csvMapper[T]{columns: make([]int, len(header)), names: header}
+	for column, name := range header {
+		mapper.columns[column] = -1
+		for i := range names {
+			if names[i] == name {
+				mapper.columns[column] = fields[i]
+				break
+			}
+		}
+	}
+	return mapper, nil
+}
+
+func (m *csvMapper[T]) mapRecord(record []string) (T, error) {
+	var value T
+	if len(record) < len(m.columns) {
+		return value, fmt.Errorf("expected %d fields, got %d", len(m.columns), len(record))
+	}
+
+	structValue := reflect.ValueOf(&value).Elem()
+	for column, fieldIndex := range m.columns {
+		if fieldIndex < 0 || record[column] == "" {
+			continue
+		}
+		if err := parseCSVField(structValue.Field(fieldIndex), record[column]); err != nil {
+			return value, fmt.Errorf("column %q: %w", m.names[column], err)
+		}
+	}
+	return value, nil
+}
+
+// record maps a struct to a record, with a column for every field
+func (m *csvMapper[T]) record(value T) ([]string, error) {
+	record := make([]string, len(m.columns))
+	structValue := reflect.ValueOf(value)
+	for column, fieldIndex := range m.columns {
+		text, err := formatCSVField(structValue.Field(fieldIndex))
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", m.names[column], err)
+		}
+		record[column] = text
+	}
+	return record, nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func canParseCSVField(fieldType reflect.Type) bool {
+	if reflect.PointerTo(fieldType).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch fieldType.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func parseCSVField(field reflect.Value, text string) error {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(text))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		field.SetBool(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(text, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if field.Type() == durationType {
+			value, err := time.ParseDuration(text)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(value))
+			return nil
+		}
+		value, err := strconv.ParseInt(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(text, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(value)
+	}
+	return nil
+}
+
+func canFormatCSVField(fieldType reflect.Type) bool {
+	if fieldType.Implements(textMarshalerType) {
+		return true
+	}
+	switch fieldType.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func formatCSVField(field reflect.Value) (string, error) {
+	if marshaler, ok := field.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'g', -1, field.Type().Bits()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if field.Type() == durationType {
+			return time.Duration(field.Int()).String(), nil
+		}
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	}
+	return "", nil
+}
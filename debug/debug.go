@@ -0,0 +1,202 @@
+// Package debug serves the live state of running pipelines over HTTP, in the spirit of net/http/pprof.
+//
+// A Handler lists every registered pipeline as JSON, with its state, its graph, the runtime metrics of its operators,
+// and the operators that seem blocked. It also includes the number of goroutines in the process. A single pipeline can be requested by appending its ID to the handler path.
+//
+// Example:
+//
+//  handler := debug.NewHandler()
+//  http.Handle("/debug/jpipe/", http.StripPrefix("/debug/jpipe", handler))
+//
+//  pipeline := jpipe.NewPipeline(jpipe.Config{Name: "ingest"})
+//  handler.Register(pipeline)
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/junitechnology/jpipe"
+)
+
+// DefaultBlockedThreshold is the BlockedThreshold of a Handler if it's not set
+const DefaultBlockedThreshold = time.Second
+
+// A Handler serves the state of the registered pipelines. It is safe to use from multiple goroutines.
+type Handler struct {
+	// BlockedThreshold is the time an operator must be continuously sending values to be reported as blocked.
+	// If 0, DefaultBlockedThreshold is used.
+	BlockedThreshold time.Duration
+
+	lock      sync.Mutex
+	pipelines []registeredPipeline
+	nextID    int
+}
+
+type registeredPipeline struct {
+	id       int
+	pipeline *jpipe.Pipeline
+}
+
+// Response is the JSON document served by a Handler
+type Response struct {
+	// ProcessGoroutines is the number of goroutines in the whole process, as returned by runtime.NumGoroutine.
+	// It is not specific to any pipeline, but a steady growth usually means some pipeline is leaking goroutines.
+	ProcessGoroutines int               `json:"process_goroutines"`
+	Pipelines         []PipelineSummary `json:"pipelines"`
+}
+
+// PipelineSummary is the state of a pipeline
+type PipelineSummary struct {
+	// ID identifies the pipeline within the Handler. IDs are assigned in registration order, starting at 1
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Started bool   `json:"started"`
+	Paused  bool   `json:"paused"`
+	Done    bool   `json:"done"`
+	// Error is the pipeline error, or empty if there is none
+	Error string      `json:"error,omitempty"`
+	Graph jpipe.Graph `json:"graph"`
+	// Mermaid is the pipeline graph rendered with jpipe.Pipeline.ExportMermaid, including counters
+	Mermaid string        `json:"mermaid"`
+	Nodes   []NodeSummary `json:"nodes"`
+	// Blocked contains the IDs of the operators that have been sending values for longer than the blocked threshold
+	Blocked []int `json:"blocked"`
+}
+
+// NodeSummary is the state of an operator, taken from jpipe.NodeStats
+type NodeSummary struct {
+	ID                 int     `json:"id"`
+	Type               string  `json:"type"`
+	Name               string  `json:"name,omitempty"`
+	ItemsIn            int64   `json:"items_in"`
+	ItemsOut           int64   `json:"items_out"`
+	LatencyMeanSeconds float64 `json:"latency_mean_seconds"`
+	SendBlockedSeconds float64 `json:"send_blocked_seconds"`
+	InputWaitSeconds   float64 `json:"input_wait_seconds"`
+	BufferSize         int     `json:"buffer_size"`
+	BufferLength       int     `json:"buffer_length"`
+	// SendingForSeconds is the time the operator has been continuously sending values, or 0 if it is not sending any
+	SendingForSeconds float64 `json:"sending_for_seconds"`
+}
+
+// NewHandler returns a Handler with no pipelines
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// Register adds a pipeline to the handler, and returns its ID. Registering a pipeline twice returns the same ID.
+// Pipelines are kept after they are done, so they can still be inspected, until they are unregistered.
+func (h *Handler) Register(pipeline *jpipe.Pipeline) int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, registered := range h.pipelines {
+		if registered.pipeline == pipeline {
+			return registered.id
+		}
+	}
+
+	h.nextID++
+	h.pipelines = append(h.pipelines, registeredPipeline{id: h.nextID, pipeline: pipeline})
+	return h.nextID
+}
+
+// Unregister removes a pipeline from the handler
+func (h *Handler) Unregister(pipeline *jpipe.Pipeline) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for i, registered := range h.pipelines {
+		if registered.pipeline == pipeline {
+			h.pipelines = append(h.pipelines[:i], h.pipelines[i+1:]...)
+			return
+		}
+	}
+}
+
+// ServeHTTP serves all pipelines at the root path, and a single pipeline at the path with its ID, e.g. "/1"
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.lock.Lock()
+	pipelines := append([]registeredPipeline{}, h.pipelines...)
+	h.lock.Unlock()
+
+	if path := strings.Trim(req.URL.Path, "/"); path != "" {
+		id, err := strconv.Atoi(path)
+		if err != nil {
+			http.Error(w, "invalid pipeline ID", http.StatusBadRequest)
+			return
+		}
+		for _, registered := range pipelines {
+			if registered.id == id {
+				h.writeJSON(w, h.summary(registered))
+				return
+			}
+		}
+		http.Error(w, "pipeline not found", http.StatusNotFound)
+		return
+	}
+
+	response := Response{ProcessGoroutines: runtime.NumGoroutine(), Pipelines: []PipelineSummary{}}
+	for _, registered := range pipelines {
+		response.Pipelines = append(response.Pipelines, h.summary(registered))
+	}
+	h.writeJSON(w, response)
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
+
+func (h *Handler) summary(registered registeredPipeline) PipelineSummary {
+	pipeline := registered.pipeline
+	summary := PipelineSummary{
+		ID:      registered.id,
+		Name:    pipeline.Name(),
+		Started: pipeline.Started(),
+		Paused:  pipeline.Paused(),
+		Done:    pipeline.IsDone(),
+		Graph:   pipeline.Graph(),
+		Mermaid: pipeline.ExportMermaid(jpipe.Counters()),
+		Nodes:   []NodeSummary{},
+		Blocked: []int{},
+	}
+	if err := pipeline.Error(); err != nil {
+		summary.Error = err.Error()
+	}
+
+	threshold := h.BlockedThreshold
+	if threshold == 0 {
+		threshold = DefaultBlockedThreshold
+	}
+	for _, node := range pipeline.Stats().Nodes {
+		var sendingFor time.Duration
+		if !node.SendingSince.IsZero() {
+			sendingFor = time.Since(node.SendingSince)
+		}
+		summary.Nodes = append(summary.Nodes, NodeSummary{
+			ID:                 node.ID,
+			Type:               node.Type,
+			Name:               node.Name,
+			ItemsIn:            node.ItemsIn,
+			ItemsOut:           node.ItemsOut,
+			LatencyMeanSeconds: node.Latency.Mean().Seconds(),
+			SendBlockedSeconds: node.SendBlocked.Seconds(),
+			InputWaitSeconds:   node.InputWait.Seconds(),
+			BufferSize:         node.BufferSize,
+			BufferLength:       node.BufferLength,
+			SendingForSeconds:  sendingFor.Seconds(),
+		})
+		if sendingFor > threshold {
+			summary.Blocked = append(summary.Blocked, node.ID)
+		}
+	}
+
+	return summary
+}
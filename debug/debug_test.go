@@ -0,0 +1,88 @@
+package debug_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/junitechnology/jpipe"
+	"github.com/junitechnology/jpipe/debug"
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, handler *debug.Handler, path string, response any) int {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+	if recorder.Code == 200 {
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+	}
+	return recorder.Code
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("Lists registered pipelines", func(t *testing.T) {
+		handler := debug.NewHandler()
+		done := jpipe.NewPipeline(jpipe.Config{Name: "done"})
+		<-jpipe.FromSlice(done, []int{1, 2, 3}).ToSlice()
+		done.Wait()
+		failed := jpipe.NewPipeline(jpipe.Config{Name: "failed", StartManually: true})
+		failed.Cancel(context.Canceled)
+		assert.Equal(t, 1, handler.Register(done))
+		assert.Equal(t, 2, handler.Register(failed))
+		assert.Equal(t, 1, handler.Register(done))
+
+		var response debug.Response
+		code := get(t, handler, "/", &response)
+
+		assert.Equal(t, 200, code)
+		assert.Greater(t, response.ProcessGoroutines, 0)
+		assert.Len(t, response.Pipelines, 2)
+		summary := response.Pipelines[0]
+		assert.Equal(t, 1, summary.ID)
+		assert.Equal(t, "done", summary.Name)
+		assert.True(t, summary.Started)
+		assert.True(t, summary.Done)
+		assert.Empty(t, summary.Error)
+		assert.Equal(t, []jpipe.GraphEdge{{From: 0, Output: 0, To: 1}}, summary.Graph.Edges)
+		assert.Contains(t, summary.Mermaid, `n1["ToSlice<br/>in: 3, out: 0"]`)
+		assert.Equal(t, int64(3), summary.Nodes[1].ItemsIn)
+		assert.Equal(t, "context canceled", response.Pipelines[1].Error)
+		assert.False(t, response.Pipelines[1].Started)
+	})
+
+	t.Run("Reports blocked operators", func(t *testing.T) {
+		handler := &debug.Handler{BlockedThreshold: 10 * time.Millisecond}
+		pipeline := jpipe.New(context.TODO())
+		goChannel := jpipe.FromRange(pipeline, 1, 10).Buffer(2).ToGoChannel()
+		id := handler.Register(pipeline)
+
+		var summary debug.PipelineSummary
+		assert.Eventually(t, func() bool { // nobody reads from goChannel, so ToGoChannel and all operators behind it get blocked
+			summary = debug.PipelineSummary{}
+			return get(t, handler, "/1", &summary) == 200 && len(summary.Blocked) == 3
+		}, time.Second, time.Millisecond)
+
+		assert.Equal(t, id, summary.ID)
+		assert.Equal(t, []int{0, 1, 2}, summary.Blocked)
+		assert.Greater(t, summary.Nodes[1].SendingForSeconds, 0.01)
+		assert.Equal(t, 2, summary.Nodes[1].BufferLength)
+		pipeline.Cancel(nil)
+		<-goChannel
+	})
+
+	t.Run("Returns an error for unknown pipelines", func(t *testing.T) {
+		handler := debug.NewHandler()
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		handler.Register(pipeline)
+		handler.Unregister(pipeline)
+
+		assert.Equal(t, 404, get(t, handler, "/1", nil))
+		assert.Equal(t, 400, get(t, handler, "/abc", nil))
+		var response debug.Response
+		get(t, handler, "/", &response)
+		assert.Empty(t, response.Pipelines)
+	})
+}
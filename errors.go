@@ -0,0 +1,95 @@
+package jpipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout is the error returned for an operator function call that exceeded the duration set with the Timeout option.
+// It wraps context.DeadlineExceeded.
+var ErrTimeout = fmt.Errorf("operator function timed out: %w", context.DeadlineExceeded)
+
+// ErrUnconsumedChannel is wrapped by the errors returned by [Pipeline.Validate] for every Channel that no operator consumes
+var ErrUnconsumedChannel = errors.New("unconsumed channel")
+
+// A PanicError is the pipeline error when an operator panics.
+// It can be retrieved from the pipeline error with errors.As.
+type PanicError struct {
+	// Value is the value recovered from the panic
+	Value any
+	// Stack is the stack trace of the goroutine that panicked
+	Stack []byte
+	// NodeType is the type of the operator that panicked, e.g. "Map"
+	NodeType string
+	// NodeID identifies the operator that panicked within its pipeline. IDs are assigned in creation order, starting at 0
+	NodeID int
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Value, e.Stack)
+}
+
+// Unwrap returns the recovered value if it is an error, so errors.Is and errors.As can inspect it
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// A StageError wraps every error coming from an operator that cancels the pipeline, identifying the failing operator.
+// It can be retrieved from the pipeline error with errors.As.
+type StageError struct {
+	// NodeType is the type of the failing operator, e.g. "Map"
+	NodeType string
+	// NodeName is the name given to the failing operator with the Name option, if any
+	NodeName string
+	// Index identifies the failing operator within its pipeline. Indexes are assigned in creation order, starting at 0
+	Index int
+	// Err is the original error
+	Err error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s: %v", nodeLabel(e.NodeType, e.NodeName, e.Index), e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// A StuckError reports an operator that has been blocked for longer than the threshold of the pipeline Watchdog.
+// It is the pipeline error if the Watchdog has FailPipeline set, so it can be retrieved with errors.As.
+type StuckError struct {
+	// NodeType is the type of the stuck operator, e.g. "Map"
+	NodeType string
+	// NodeName is the name given to the stuck operator with the Name option, if any
+	NodeName string
+	// NodeID identifies the stuck operator within its pipeline. IDs are assigned in creation order, starting at 0
+	NodeID int
+	// Path is the chain of operators from a source to the stuck one, e.g. `FromSlice (#0) -> Map "parse" (#1)`
+	Path string
+	// Sending is true if the operator is blocked sending values, i.e. they are not being read downstream,
+	// and false if it is blocked waiting for input values
+	Sending bool
+	// Duration is the time the operator has been blocked when it was reported
+	Duration time.Duration
+}
+
+func (e *StuckError) Error() string {
+	blockedIn := "waiting for input values"
+	if e.Sending {
+		blockedIn = "sending values"
+	}
+	return fmt.Sprintf("%s stuck %s for %v, path: %s", nodeLabel(e.NodeType, e.NodeName, e.NodeID), blockedIn, e.Duration, e.Path)
+}
+
+// nodeLabel identifies an operator in error messages
+func nodeLabel(nodeType string, name string, id int) string {
+	if name != "" {
+		return fmt.Sprintf("%s %q (#%d)", nodeType, name, id)
+	}
+	return fmt.Sprintf("%s (#%d)", nodeType, id)
+}
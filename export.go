@@ -0,0 +1,116 @@
+package jpipe
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/junitechnology/jpipe/options"
+)
+
+// ExportMermaid renders the pipeline graph as a [Mermaid] flowchart.
+// Every operator shows its type, its name and its concurrency and buffer size if set.
+// With the Counters option, it also shows the number of values read and sent by every operator so far.
+// The inner channels of FlatMap that are currently running are grouped in a subgraph, and unconsumed channels are rendered as dashed edges.
+//
+// Example:
+//
+//  flowchart LR
+//    n0["FromSlice"]
+//    n1["Map"]
+//    n2["ForEach"]
+//    n0 --> n1
+//    n1 --> n2
+//
+// [Mermaid]: https://mermaid.js.org/syntax/flowchart.html
+func (p *Pipeline) ExportMermaid(opts ...options.ExportOption) string {
+	graph := p.Graph()
+	counters := getOption[options.ExportOption, options.Counters](opts) != nil
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	writeNodes := func(nodes []GraphNode, indent string) {
+		for _, node := range nodes {
+			label := strings.ReplaceAll(strings.Join(graphNodeLabel(node, counters), "<br/>"), `"`, "#quot;")
+			fmt.Fprintf(&sb, "%sn%d[\"%s\"]\n", indent, node.ID, label)
+		}
+	}
+	writeNodes(graph.staticNodes(), "  ")
+	for _, subgraph := range graph.subgraphs() {
+		fmt.Fprintf(&sb, "  subgraph n%d_inner [\"%s inner channels\"]\n", subgraph.parent, graph.nodeType(subgraph.parent))
+		writeNodes(subgraph.nodes, "    ")
+		sb.WriteString("  end\n")
+	}
+	for _, edge := range graph.Edges {
+		if graph.isFanOut(edge.From) {
+			fmt.Fprintf(&sb, "  n%d -->|%d| n%d\n", edge.From, edge.Output, edge.To)
+		} else {
+			fmt.Fprintf(&sb, "  n%d --> n%d\n", edge.From, edge.To)
+		}
+	}
+	for _, output := range graph.Unconsumed {
+		fmt.Fprintf(&sb, "  n%d_%d((\" \"))\n", output.From, output.Output)
+		fmt.Fprintf(&sb, "  n%d -.-> n%d_%d\n", output.From, output.From, output.Output)
+	}
+
+	return sb.String()
+}
+
+// dotEscaper escapes DOT quoted strings. Backslashes are escaped too, and all replacements are done in a single pass,
+// so the backslashes added for quotes and newlines are not escaped again.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// ExportDOT renders the pipeline graph in the [DOT] language of Graphviz.
+// It shows the same information as ExportMermaid, with the inner channels of FlatMap grouped in clusters.
+//
+// [DOT]: https://graphviz.org/doc/info/lang.html
+func (p *Pipeline) ExportDOT(opts ...options.ExportOption) string {
+	graph := p.Graph()
+	counters := getOption[options.ExportOption, options.Counters](opts) != nil
+
+	var sb strings.Builder
+	sb.WriteString("digraph {\n  rankdir=LR;\n")
+	writeNodes := func(nodes []GraphNode, indent string) {
+		for _, node := range nodes {
+			label := dotEscaper.Replace(strings.Join(graphNodeLabel(node, counters), "\n"))
+			fmt.Fprintf(&sb, "%sn%d [label=\"%s\", shape=box];\n", indent, node.ID, label)
+		}
+	}
+	writeNodes(graph.staticNodes(), "  ")
+	for _, subgraph := range graph.subgraphs() {
+		fmt.Fprintf(&sb, "  subgraph cluster_n%d {\n    label=\"%s inner channels\";\n", subgraph.parent, graph.nodeType(subgraph.parent))
+		writeNodes(subgraph.nodes, "    ")
+		sb.WriteString("  }\n")
+	}
+	for _, edge := range graph.Edges {
+		if graph.isFanOut(edge.From) {
+			fmt.Fprintf(&sb, "  n%d -> n%d [label=\"%d\"];\n", edge.From, edge.To, edge.Output)
+		} else {
+			fmt.Fprintf(&sb, "  n%d -> n%d;\n", edge.From, edge.To)
+		}
+	}
+	for _, output := range graph.Unconsumed {
+		fmt.Fprintf(&sb, "  n%d_%d [label=\"\", shape=point];\n", output.From, output.Output)
+		fmt.Fprintf(&sb, "  n%d -> n%d_%d [style=dashed];\n", output.From, output.From, output.Output)
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+func graphNodeLabel(node GraphNode, counters bool) []string {
+	lines := []string{node.Type}
+	if node.Name != "" {
+		lines[0] = fmt.Sprintf("%s %q", node.Type, node.Name)
+	}
+	if node.Concurrency > 1 {
+		lines = append(lines, fmt.Sprintf("concurrency: %d", node.Concurrency))
+	}
+	if node.BufferSize > 0 {
+		lines = append(lines, fmt.Sprintf("buffer: %d", node.BufferSize))
+	}
+	if counters {
+		lines = append(lines, fmt.Sprintf("in: %d, out: %d", node.ItemsIn, node.ItemsOut))
+	}
+
+	return lines
+}
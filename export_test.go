@@ -0,0 +1,119 @@
+package jpipe_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/junitechnology/jpipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineExportMermaid(t *testing.T) {
+	t.Run("Renders fan-out and fan-in", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		channels := jpipe.FromSlice(pipeline, []int{1, 2, 3}).Broadcast(2)
+		mapped := jpipe.Map(channels[0], func(i int) int { return i * 10 }, jpipe.Concurrent(2), jpipe.Name("times ten"))
+		jpipe.Merge(mapped, channels[1].Buffer(5)).ForEach(func(i int) {})
+
+		mermaid := pipeline.ExportMermaid()
+
+		assert.Equal(t, `flowchart LR
+  n0["FromSlice"]
+  n1["Broadcast"]
+  n2["Map #quot;times ten#quot;<br/>concurrency: 2"]
+  n3["Buffer<br/>buffer: 5"]
+  n4["Merge"]
+  n5["ForEach"]
+  n0 --> n1
+  n1 -->|0| n2
+  n1 -->|1| n3
+  n2 --> n4
+  n3 --> n4
+  n4 --> n5
+`, mermaid)
+		cancelPipeline(pipeline)
+	})
+
+	t.Run("Renders counters and unconsumed channels", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		channels := jpipe.FromSlice(pipeline, []int{1, 2, 3}).Split(2)
+		channels[0].ForEach(func(i int) {})
+		pipeline.Start()
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+
+		mermaid := pipeline.ExportMermaid(jpipe.Counters())
+
+		assert.Equal(t, `flowchart LR
+  n0["FromSlice<br/>in: 0, out: 3"]
+  n1["Split<br/>in: 3, out: 3"]
+  n2["ForEach<br/>in: 3, out: 0"]
+  n0 --> n1
+  n1 -->|0| n2
+  n1_1((" "))
+  n1 -.-> n1_1
+`, mermaid)
+	})
+
+	t.Run("Renders FlatMap inner channels in a subgraph", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		blocked := make(chan int)
+		channel := jpipe.FlatMap(jpipe.FromSlice(pipeline, []int{1}), func(i int) *jpipe.Channel[int] {
+			return jpipe.FromGoChannel(pipeline, blocked)
+		})
+		goChannel := channel.ToGoChannel()
+		blocked <- 1
+		<-goChannel
+
+		mermaid := pipeline.ExportMermaid()
+
+		assert.Equal(t, `flowchart LR
+  n0["FromSlice"]
+  n1["FlatMap"]
+  n2["ToGoChannel"]
+  subgraph n1_inner ["FlatMap inner channels"]
+    n3["FromGoChannel"]
+  end
+  n0 --> n1
+  n1 --> n2
+  n3 --> n1
+`, mermaid)
+		close(blocked)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NotContains(t, pipeline.ExportMermaid(), "n3")
+	})
+}
+
+func TestPipelineExportDOT(t *testing.T) {
+	t.Run("Renders nodes and edges", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		channels := jpipe.FromSlice(pipeline, []int{1, 2, 3}).Split(2, jpipe.Buffered(2))
+		channels[0].ForEach(func(i int) {}, jpipe.Name("first"))
+		channels[1].ForEach(func(i int) {})
+
+		dot := pipeline.ExportDOT()
+
+		assert.Equal(t, `digraph {
+  rankdir=LR;
+  n0 [label="FromSlice", shape=box];
+  n1 [label="Split\nbuffer: 2", shape=box];
+  n2 [label="ForEach \"first\"", shape=box];
+  n3 [label="ForEach", shape=box];
+  n0 -> n1;
+  n1 -> n2 [label="0"];
+  n1 -> n3 [label="1"];
+}
+`, dot)
+		cancelPipeline(pipeline)
+	})
+
+	t.Run("Escapes labels", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		jpipe.FromSlice(pipeline, []int{1, 2, 3}).ForEach(func(i int) {}, jpipe.Name(`C:\tmp`))
+
+		dot := pipeline.ExportDOT()
+
+		assert.Contains(t, dot, `n1 [label="ForEach \"C:\\\\tmp\"", shape=box];`) // the name is quoted as a Go string, and then escaped for DOT
+		cancelPipeline(pipeline)
+	})
+}
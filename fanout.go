@@ -1,6 +1,9 @@
 package jpipe
 
-import "github.com/junitechnology/jpipe/options"
+import (
+	"github.com/junitechnology/jpipe/item"
+	"github.com/junitechnology/jpipe/options"
+)
 
 // Split sends each input value to any of the output channels, with no specific priority.
 //
@@ -45,3 +48,26 @@ func (input *Channel[T]) Broadcast(numOutputs int, opts ...options.BroadcastOpti
 	_, outputs := newPipelineNode("Broadcast", input.getPipeline(), []*Channel[T]{input}, numOutputs, worker, false, getNodeOptions(opts)...)
 	return outputs
 }
+
+// DeadLetter routes input items with no error to the first output channel, and errored items to the second(dead-letter) output channel.
+//
+// Example:
+//
+//  values, deadLetters := DeadLetter(input)
+//
+//  input      : 0--1--E1--3--E2--5--X
+//  values     : 0--1------3------5--X
+//  deadLetters: ------E1------E2----X
+func DeadLetter[T any](input *Channel[item.Item[T]], opts ...options.DeadLetterOption) (*Channel[item.Item[T]], *Channel[item.Item[T]]) {
+	worker := func(node workerNode[item.Item[T], item.Item[T]]) {
+		node.LoopInput(0, func(value item.Item[T]) bool {
+			if value.Error != nil {
+				return node.SendTo(1, value)
+			}
+			return node.SendTo(0, value)
+		})
+	}
+
+	_, outputs := newPipelineNode("DeadLetter", input.getPipeline(), []*Channel[item.Item[T]]{input}, 2, worker, false, getNodeOptions(opts)...)
+	return outputs[0], outputs[1]
+}
@@ -1,11 +1,13 @@
 package jpipe_test
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/junitechnology/jpipe"
+	"github.com/junitechnology/jpipe/item"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/exp/slices"
 )
@@ -98,3 +100,39 @@ func TestBroadcast(t *testing.T) {
 		assertPipelineDone(t, pipeline, 10*time.Millisecond)
 	})
 }
+
+func TestDeadLetter(t *testing.T) {
+	t.Run("Routes errored items to the dead-letter channel", func(t *testing.T) {
+		errTest := errors.New("test error")
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		channel := jpipe.FromSlice(pipeline, []item.Item[int]{
+			jpipe.ValueItem(1),
+			jpipe.ErrorItem[int](errTest),
+			jpipe.ValueItem(3),
+		})
+		values, deadLetters := jpipe.DeadLetter(channel)
+		valuesSlice := values.ToSlice()
+		deadLettersSlice := deadLetters.ToSlice()
+		pipeline.Start()
+
+		assert.Equal(t, []item.Item[int]{{Value: 1}, {Value: 3}}, <-valuesSlice)
+		assert.Equal(t, []item.Item[int]{{Error: errTest}}, <-deadLettersSlice)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Exits early if pipeline canceled", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		channel := jpipe.Wrap(jpipe.FromRange(pipeline, 1, 10))
+		values, deadLetters := jpipe.DeadLetter(channel)
+		goChannel := values.ToGoChannel()
+		deadLettersGoChannel := deadLetters.ToGoChannel()
+		pipeline.Start()
+
+		readGoChannel(goChannel, 2)
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertChannelClosed(t, deadLettersGoChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+}
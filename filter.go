@@ -1,6 +1,10 @@
 package jpipe
 
-import "github.com/junitechnology/jpipe/options"
+import (
+	"context"
+
+	"github.com/junitechnology/jpipe/options"
+)
 
 // Filter sends to the output channel only the input values that match the predicate.
 //
@@ -11,12 +15,42 @@ import "github.com/junitechnology/jpipe/options"
 //  input : 0--1--2--3--4--5-X
 //  output: ---1-----3-----5-X
 func (input *Channel[T]) Filter(predicate func(T) bool, opts ...options.FilterOption) *Channel[T] {
-	var processor processor[T, T] = func(value T) (T, bool) {
-		return value, predicate(value)
+	var function operatorFunc[T, bool] = func(_ context.Context, value T) (bool, error) {
+		return predicate(value), nil
+	}
+	worker := filterWorker(function, getPooledWorkerOptions(opts)...)
+
+	_, output := newLinearPipelineNode("Filter", input, worker, getNodeOptions(opts)...)
+	return output
+}
+
+// FilterErr sends to the output channel only the input values that match a predicate that may fail.
+// If the predicate returns an error, the pipeline is canceled with that error and no more values are processed.
+//
+// Example:
+//
+//  output := input.FilterErr(func(s string) (bool, error) { return strconv.ParseBool(s) })
+//
+//  input : true--false--true--A--true-X
+//  output: true---------true--X
+func (input *Channel[T]) FilterErr(predicate func(T) (bool, error), opts ...options.FilterOption) *Channel[T] {
+	var function operatorFunc[T, bool] = func(_ context.Context, value T) (bool, error) {
+		return predicate(value)
 	}
-	worker := processor.PooledWorker(getPooledWorkerOptions(opts)...)
+	worker := filterWorker(function, getPooledWorkerOptions(opts)...)
+
+	_, output := newLinearPipelineNode("FilterErr", input, worker, getNodeOptions(opts)...)
+	return output
+}
+
+// FilterCtx works like FilterErr, but the predicate also receives a context.
+// The context is canceled when the pipeline is canceled or the operator quits, so it can be used to abort any I/O in the predicate.
+// It also expires after the duration set with the Timeout option, if any.
+func (input *Channel[T]) FilterCtx(predicate func(context.Context, T) (bool, error), opts ...options.FilterOption) *Channel[T] {
+	var function operatorFunc[T, bool] = predicate
+	worker := filterWorker(function, getPooledWorkerOptions(opts)...)
 
-	_, output := newLinearPipelineNode("Filter", input, worker)
+	_, output := newLinearPipelineNode("FilterCtx", input, worker, getNodeOptions(opts)...)
 	return output
 }
 
@@ -2,6 +2,7 @@ package jpipe_test
 
 import (
 	"context"
+	"strconv"
 	"testing"
 	"time"
 
@@ -87,6 +88,45 @@ func TestFilter(t *testing.T) {
 	})
 }
 
+func TestFilterErr(t *testing.T) {
+	t.Run("Filters values", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []string{"true", "false", "true"}).
+			FilterErr(func(s string) (bool, error) { return strconv.ParseBool(s) })
+
+		filteredValues := drainChannel(channel)
+
+		assert.Equal(t, []string{"true", "true"}, filteredValues)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []string{"true", "false", "A", "true"}).
+			FilterErr(func(s string) (bool, error) { return strconv.ParseBool(s) }, jpipe.Concurrent(2))
+
+		<-channel.ToSlice()
+
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), strconv.ErrSyntax)
+	})
+}
+
+func TestFilterCtx(t *testing.T) {
+	t.Run("Filters values", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3}).
+			FilterCtx(func(ctx context.Context, i int) (bool, error) { return i%2 == 1, ctx.Err() })
+
+		filteredValues := drainChannel(channel)
+
+		assert.Equal(t, []int{1, 3}, filteredValues)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
+
 func TestSkip(t *testing.T) {
 	t.Run("Skips n values", func(t *testing.T) {
 		pipeline := jpipe.New(context.TODO())
@@ -0,0 +1,190 @@
+package jpipe
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A Graph is a snapshot of the topology of a pipeline, as returned by [Pipeline.Graph]
+type Graph struct {
+	// Nodes contains every operator in the pipeline, in creation order
+	Nodes []GraphNode `json:"nodes"`
+	// Edges contains every Channel connecting two operators
+	Edges []GraphEdge `json:"edges"`
+	// Unconsumed contains every Channel that no operator consumes
+	Unconsumed []GraphOutput `json:"unconsumed"`
+}
+
+// A GraphNode describes an operator in a pipeline
+type GraphNode struct {
+	// ID identifies the operator within its pipeline. IDs are assigned in creation order, starting at 0
+	ID int `json:"id"`
+	// Type is the type of the operator, e.g. "Map"
+	Type string `json:"type"`
+	// Name is the name set with the Name option, if any
+	Name string `json:"name,omitempty"`
+	// Concurrency is the concurrency set with the Concurrent option, or 1 if not set
+	Concurrency int `json:"concurrency"`
+	// BufferSize is the buffer size of the output channels of the operator, or 0 if they are unbuffered
+	BufferSize int `json:"buffer_size"`
+	// Inputs is the number of input channels of the operator
+	Inputs int `json:"inputs"`
+	// Outputs is the number of output channels of the operator
+	Outputs int `json:"outputs"`
+	// Dynamic is whether the operator was created after the pipeline started, like the inner channels of FlatMap
+	Dynamic bool `json:"dynamic"`
+	// ItemsIn is the number of values the operator has read from its inputs so far
+	ItemsIn int64 `json:"items_in"`
+	// ItemsOut is the number of values the operator has sent to its outputs so far
+	ItemsOut int64 `json:"items_out"`
+}
+
+// IsSource returns whether the operator is a source, i.e. it has no inputs
+func (n GraphNode) IsSource() bool {
+	return n.Inputs == 0
+}
+
+// IsSink returns whether the operator is a sink, i.e. it has no outputs
+func (n GraphNode) IsSink() bool {
+	return n.Outputs == 0
+}
+
+// A GraphEdge describes a Channel connecting two operators
+type GraphEdge struct {
+	// From is the ID of the operator writing to the Channel
+	From int `json:"from"`
+	// Output is the index of the Channel among the outputs of the From operator, e.g. the i-th Channel returned by Split
+	Output int `json:"output"`
+	// To is the ID of the operator reading from the Channel
+	To int `json:"to"`
+}
+
+// A GraphOutput identifies an output Channel of an operator
+type GraphOutput struct {
+	// From is the ID of the operator writing to the Channel
+	From int `json:"from"`
+	// Output is the index of the Channel among the outputs of the From operator
+	Output int `json:"output"`
+}
+
+// Graph returns a snapshot of the pipeline topology.
+// Operators created after the pipeline started, like the inner channels of FlatMap, are only included while they are running.
+//
+// Example:
+//
+//  graph := pipeline.Graph()
+//  if len(graph.Unconsumed) > 0 {
+//    return fmt.Errorf("pipeline has %d unconsumed channels", len(graph.Unconsumed))
+//  }
+func (p *Pipeline) Graph() Graph {
+	staticNodes, dynamicNodes := p.nodesSnapshot()
+
+	graph := Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}, Unconsumed: []GraphOutput{}}
+	for i, node := range append(staticNodes, dynamicNodes...) {
+		graphNode := node.GraphNode()
+		graphNode.Dynamic = i >= len(staticNodes)
+		graph.Nodes = append(graph.Nodes, graphNode)
+		for output, child := range node.GraphChildren() {
+			if child == nil {
+				graph.Unconsumed = append(graph.Unconsumed, GraphOutput{From: graphNode.ID, Output: output})
+				continue
+			}
+			graph.Edges = append(graph.Edges, GraphEdge{From: graphNode.ID, Output: output, To: child.GraphNode().ID})
+		}
+	}
+
+	return graph
+}
+
+// Validate returns an error if the pipeline has any Channel that no operator consumes, as such a pipeline never finishes.
+// The error joins an error wrapping ErrUnconsumedChannel for every unconsumed Channel.
+// It is meant to be called after building the pipeline and before starting it, or automatically on start with Validate set in the pipeline's Config.
+func (p *Pipeline) Validate() error {
+	graph := p.Graph()
+	errs := []error{}
+	for _, output := range graph.Unconsumed {
+		node, _ := graph.nodeByID(output.From)
+		errs = append(errs, fmt.Errorf("%w: output %d of %s", ErrUnconsumedChannel, output.Output, nodeLabel(node.Type, node.Name, node.ID)))
+	}
+
+	return errors.Join(errs...)
+}
+
+// graphSubgraph groups the dynamic nodes feeding the same static node, like the inner channels of a FlatMap
+type graphSubgraph struct {
+	parent int
+	nodes  []GraphNode
+}
+
+func (g Graph) nodeByID(id int) (GraphNode, bool) {
+	for _, node := range g.Nodes {
+		if node.ID == id {
+			return node, true
+		}
+	}
+
+	return GraphNode{}, false
+}
+
+// staticNodes returns the nodes not belonging to any subgraph
+func (g Graph) staticNodes() []GraphNode {
+	nodes := []GraphNode{}
+	for _, node := range g.Nodes {
+		if g.subgraphParent(node) < 0 {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+func (g Graph) subgraphs() []graphSubgraph {
+	subgraphs := []graphSubgraph{}
+	indexes := map[int]int{}
+	for _, node := range g.Nodes {
+		parent := g.subgraphParent(node)
+		if parent < 0 {
+			continue
+		}
+		if _, ok := indexes[parent]; !ok {
+			indexes[parent] = len(subgraphs)
+			subgraphs = append(subgraphs, graphSubgraph{parent: parent})
+		}
+		subgraphs[indexes[parent]].nodes = append(subgraphs[indexes[parent]].nodes, node)
+	}
+
+	return subgraphs
+}
+
+// subgraphParent follows the edges from a dynamic node until it finds a static node, and returns its ID.
+// It returns -1 for static nodes and for dynamic nodes not feeding any static node.
+func (g Graph) subgraphParent(node GraphNode) int {
+	if !node.Dynamic {
+		return -1
+	}
+
+	for node.Dynamic {
+		found := false
+		for _, edge := range g.Edges {
+			if edge.From == node.ID {
+				node, found = g.nodeByID(edge.To)
+				break
+			}
+		}
+		if !found {
+			return -1
+		}
+	}
+
+	return node.ID
+}
+
+func (g Graph) nodeType(id int) string {
+	node, _ := g.nodeByID(id)
+	return node.Type
+}
+
+func (g Graph) isFanOut(id int) bool {
+	node, _ := g.nodeByID(id)
+	return node.Outputs > 1
+}
@@ -0,0 +1,72 @@
+package jpipe_test
+
+import (
+	"testing"
+
+	"github.com/junitechnology/jpipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineGraph(t *testing.T) {
+	t.Run("Returns nodes and edges", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3}).
+			Filter(func(i int) bool { return i > 1 }, jpipe.Concurrent(3), jpipe.Name("filter"))
+		splitChannels := channel.Split(2, jpipe.Buffered(5))
+		splitChannels[0].ForEach(func(i int) {})
+		splitChannels[1].ForEach(func(i int) {})
+
+		graph := pipeline.Graph()
+
+		assert.Equal(t, []jpipe.GraphNode{
+			{ID: 0, Type: "FromSlice", Concurrency: 1, Outputs: 1},
+			{ID: 1, Type: "Filter", Name: "filter", Concurrency: 3, Inputs: 1, Outputs: 1},
+			{ID: 2, Type: "Split", Concurrency: 1, BufferSize: 5, Inputs: 1, Outputs: 2},
+			{ID: 3, Type: "ForEach", Concurrency: 1, Inputs: 1},
+			{ID: 4, Type: "ForEach", Concurrency: 1, Inputs: 1},
+		}, graph.Nodes)
+		assert.Equal(t, []jpipe.GraphEdge{
+			{From: 0, Output: 0, To: 1},
+			{From: 1, Output: 0, To: 2},
+			{From: 2, Output: 0, To: 3},
+			{From: 2, Output: 1, To: 4},
+		}, graph.Edges)
+		assert.Empty(t, graph.Unconsumed)
+		assert.True(t, graph.Nodes[0].IsSource())
+		assert.True(t, graph.Nodes[3].IsSink())
+		cancelPipeline(pipeline)
+	})
+
+	t.Run("Returns unconsumed channels", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		broadcastChannels := jpipe.FromSlice(pipeline, []int{1, 2, 3}).Broadcast(2)
+		broadcastChannels[1].ForEach(func(i int) {})
+
+		graph := pipeline.Graph()
+
+		assert.Equal(t, []jpipe.GraphOutput{{From: 1, Output: 0}}, graph.Unconsumed)
+		cancelPipeline(pipeline)
+	})
+}
+
+func TestPipelineValidate(t *testing.T) {
+	t.Run("Returns nil if all channels are consumed", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		jpipe.FromSlice(pipeline, []int{1, 2, 3}).ForEach(func(i int) {})
+
+		assert.NoError(t, pipeline.Validate())
+		cancelPipeline(pipeline)
+	})
+
+	t.Run("Returns an error for every unconsumed channel", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		channels := jpipe.FromSlice(pipeline, []int{1, 2, 3}).Broadcast(3, jpipe.Name("fanout"))
+		channels[1].ForEach(func(i int) {})
+
+		err := pipeline.Validate()
+
+		assert.ErrorIs(t, err, jpipe.ErrUnconsumedChannel)
+		assert.Equal(t, "unconsumed channel: output 0 of Broadcast \"fanout\" (#1)\nunconsumed channel: output 2 of Broadcast \"fanout\" (#1)", err.Error())
+		cancelPipeline(pipeline)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/junitechnology/jpipe/item"
+	"github.com/junitechnology/jpipe/options"
 )
 
 func Item[T any](value T, err error, ctx context.Context) item.Item[T] {
@@ -21,3 +22,53 @@ func ValueItem[T any](value T) item.Item[T] {
 func ErrorItem[T any](err error) item.Item[T] {
 	return item.Item[T]{Error: err}
 }
+
+// startItemSpan starts a span for the item if the operator is traced, and returns the item with the span context.
+// The returned function ends the span, recording the error if not nil. It must always be called.
+func startItemSpan[T any](traced *options.Traced, spanName string, it item.Item[T]) (item.Item[T], func(err error)) {
+	if traced == nil {
+		return it, func(error) {}
+	}
+
+	ctx, endSpan := traced.Start(it.Ctx, spanName)
+	it.Ctx = ctx
+	return it, endSpan
+}
+
+// itemContext returns a context for an operator function processing an item.
+// It is canceled as ctx, the node context, but it has the values of the item context, like the span started for the item.
+func itemContext(ctx context.Context, itemCtx context.Context) context.Context {
+	if itemCtx == nil {
+		return ctx
+	}
+	return itemValuesContext{Context: ctx, values: itemCtx}
+}
+
+// itemValuesContext looks up values in the item context first, and then in the node context
+type itemValuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c itemValuesContext) Value(key any) any {
+	if value := c.values.Value(key); value != nil {
+		return value
+	}
+	return c.Context.Value(key)
+}
+
+// itemErrorProcessor returns a processor that handles an errored item according to the error strategy.
+// It receives the node context, as any processor, and the context of the item.
+func itemErrorProcessor[R any](onError options.OnError) func(ctx context.Context, err error, itemCtx context.Context) (item.Item[R], bool, error) {
+	return func(ctx context.Context, err error, itemCtx context.Context) (item.Item[R], bool, error) {
+		switch onError.Strategy {
+		case options.FAIL_FAST:
+			return item.Item[R]{}, false, err
+		case options.SKIP_ERRORS:
+			logDroppedItem(ctx, err)
+			return item.Item[R]{}, false, nil
+		default:
+			return item.Item[R]{Error: err, Ctx: itemCtx}, true, nil
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package jpipe
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/junitechnology/jpipe/options"
+)
+
+type loggerKey struct{}
+
+// contextWithLogger returns a context carrying the logger of a node, so operator functions decorated with options like Retry can log
+func contextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// loggerFromContext returns the logger of the node the context belongs to, or nil if the node doesn't log
+func loggerFromContext(ctx context.Context) *slog.Logger {
+	logger, _ := ctx.Value(loggerKey{}).(*slog.Logger)
+	return logger
+}
+
+// withPipelineAttrs returns a logger adding the pipeline name to every record, if it has one
+func withPipelineAttrs(logger *slog.Logger, pipelineName string) *slog.Logger {
+	if pipelineName == "" {
+		return logger
+	}
+	return logger.With("pipeline", pipelineName)
+}
+
+// nodeLogger returns the logger for a node, adding its type, name and ID to every record.
+// It returns nil if the node must not log.
+func nodeLogger(pipeline *Pipeline, id int, nodeType string, name string, logOpt *options.Log) *slog.Logger {
+	logger := pipeline.logger
+	if logger == nil {
+		if logOpt == nil {
+			return nil
+		}
+		logger = withPipelineAttrs(slog.Default(), pipeline.Name())
+	}
+
+	logger = logger.With("node_type", nodeType, "node_id", id)
+	if name != "" {
+		logger = logger.With("node_name", name)
+	}
+	return logger
+}
+
+// valueLogLevel returns the level the values read by operators with the Log option are logged at.
+// It's Debug with the logger in the pipeline Config, but Info with slog.Default(), as its handler drops Debug records.
+func valueLogLevel(pipeline *Pipeline) slog.Level {
+	if pipeline.logger == nil {
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug
+}
+
+// logDroppedItem logs an errored item dropped by the SkipErrors strategy
+func logDroppedItem(ctx context.Context, err error) {
+	if logger := loggerFromContext(ctx); logger != nil {
+		logger.Warn("errored item dropped", "error", err)
+	}
+}
@@ -0,0 +1,147 @@
+package jpipe_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junitechnology/jpipe"
+	"github.com/junitechnology/jpipe/item"
+	"github.com/stretchr/testify/assert"
+)
+
+type logBuffer struct {
+	lock   sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buffer.Write(p)
+}
+
+// records returns the logged records with the given message, without their time
+func (b *logBuffer) records(t *testing.T, msg string) []map[string]any {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	records := []map[string]any{}
+	for _, line := range bytes.Split(bytes.TrimSpace(b.buffer.Bytes()), []byte("\n")) {
+		record := map[string]any{}
+		assert.NoError(t, json.Unmarshal(line, &record))
+		delete(record, "time")
+		if record["msg"] == msg {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func newTestLogger(buffer *logBuffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("Logs the pipeline lifecycle", func(t *testing.T) {
+		buffer := &logBuffer{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Name: "orders", Logger: newTestLogger(buffer)})
+		jpipe.FromSlice(pipeline, []int{1, 2}).ForEach(func(i int) {}, jpipe.Name("noop"))
+		assert.NoError(t, pipeline.Wait())
+
+		assert.Len(t, buffer.records(t, "pipeline started"), 1)
+		assert.Len(t, buffer.records(t, "pipeline done"), 1)
+		started := buffer.records(t, "operator started")
+		assert.Len(t, started, 2)
+		assert.Contains(t, started, map[string]any{
+			"level":     "DEBUG",
+			"msg":       "operator started",
+			"pipeline":  "orders",
+			"node_type": "ForEach",
+			"node_id":   float64(1),
+			"node_name": "noop",
+		})
+		assert.Len(t, buffer.records(t, "operator done"), 2)
+		assert.Empty(t, buffer.records(t, "value received"))
+	})
+
+	t.Run("Logs the cancellation cause and panics", func(t *testing.T) {
+		buffer := &logBuffer{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Logger: newTestLogger(buffer)})
+		jpipe.FromSlice(pipeline, []int{1}).ForEach(func(i int) { panic("boom") })
+		assert.Error(t, pipeline.Wait())
+
+		panicked := buffer.records(t, "operator panicked")
+		assert.Len(t, panicked, 1)
+		assert.Equal(t, "ERROR", panicked[0]["level"])
+		assert.Equal(t, "boom", panicked[0]["panic"])
+		assert.Contains(t, panicked[0]["stack"], "goroutine")
+		canceled := buffer.records(t, "pipeline canceled")
+		assert.Len(t, canceled, 1)
+		assert.Equal(t, pipeline.Error().Error(), canceled[0]["error"])
+	})
+
+	t.Run("Logs retries and dropped items", func(t *testing.T) {
+		buffer := &logBuffer{}
+		errTest := errors.New("test error")
+		pipeline := jpipe.NewPipeline(jpipe.Config{Logger: newTestLogger(buffer)})
+		channel := jpipe.FromSlice(pipeline, []item.Item[string]{jpipe.ValueItem("A"), jpipe.ErrorItem[string](errTest)})
+		mapped := jpipe.MapItems(channel, strconv.Atoi, jpipe.SkipErrors(), jpipe.Retry(2).WithBackoff(time.Millisecond, time.Millisecond, 1))
+		<-mapped.ToSlice()
+		assert.NoError(t, pipeline.Wait())
+
+		retries := buffer.records(t, "retrying operator function")
+		assert.Len(t, retries, 1)
+		assert.Equal(t, "MapItems", retries[0]["node_type"])
+		assert.Equal(t, float64(1), retries[0]["attempt"])
+		dropped := buffer.records(t, "errored item dropped")
+		assert.Len(t, dropped, 2)
+		assert.Contains(t, dropped[0]["error"], "invalid syntax")
+		assert.Equal(t, "test error", dropped[1]["error"])
+	})
+
+	t.Run("Logs sampled values with LogEvery", func(t *testing.T) {
+		buffer := &logBuffer{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Logger: newTestLogger(buffer)})
+		channel := jpipe.FromRange(pipeline, 1, 5).Filter(func(i int) bool { return true }, jpipe.LogEvery(2))
+		<-channel.ToSlice()
+		assert.NoError(t, pipeline.Wait())
+
+		received := buffer.records(t, "value received")
+		assert.Len(t, received, 3)
+		for i, record := range received {
+			assert.Equal(t, "Filter", record["node_type"])
+			assert.Equal(t, float64(2*i+1), record["value"])
+			assert.Equal(t, float64(2*i+1), record["count"])
+		}
+	})
+
+	t.Run("Logs values only for operators with the Log option", func(t *testing.T) {
+		buffer := &logBuffer{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Logger: newTestLogger(buffer)})
+		<-jpipe.FromSlice(pipeline, []int{1, 2}).Tap(func(i int) {}, jpipe.Log()).ToSlice()
+		assert.NoError(t, pipeline.Wait())
+
+		received := buffer.records(t, "value received")
+		assert.Len(t, received, 2)
+		for i, record := range received {
+			assert.Equal(t, "Tap", record["node_type"])
+			assert.Equal(t, "DEBUG", record["level"])
+			assert.Equal(t, float64(i+1), record["value"])
+		}
+	})
+
+	t.Run("Log doesn't log values above Debug level", func(t *testing.T) {
+		buffer := &logBuffer{}
+		logger := slog.New(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelInfo}))
+		pipeline := jpipe.NewPipeline(jpipe.Config{Logger: logger})
+		<-jpipe.FromSlice(pipeline, []int{1, 2}).Tap(func(i int) {}, jpipe.Log()).ToSlice()
+		assert.NoError(t, pipeline.Wait())
+
+		assert.Empty(t, buffer.buffer.String())
+	})
+}
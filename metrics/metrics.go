@@ -0,0 +1,237 @@
+// Package metrics exports the runtime metrics of pipelines in the [Prometheus text exposition format].
+//
+// Metrics are taken from [jpipe.Pipeline.Stats] on every scrape, so registering a pipeline has no overhead on it.
+// Every series is labeled with the pipeline name, and the operator type, name and ID, so registered pipelines must have unique names.
+//
+// Example:
+//
+//  registry := metrics.NewRegistry()
+//  pipeline := jpipe.NewPipeline(jpipe.Config{Name: "ingest"})
+//  if err := registry.Register(pipeline); err != nil {
+//    return err
+//  }
+//  http.Handle("/metrics", registry)
+//
+// [Prometheus text exposition format]: https://prometheus.io/docs/instrumenting/exposition_formats/#text-based-format
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/junitechnology/jpipe"
+)
+
+var (
+	// ErrUnnamedPipeline is returned when registering a pipeline with no name, as its series couldn't be told apart from others
+	ErrUnnamedPipeline = errors.New("pipeline has no name")
+	// ErrDuplicatePipelineName is returned when registering a pipeline with the same name as another registered one, as they would have the same series
+	ErrDuplicatePipelineName = errors.New("a pipeline with the same name is already registered")
+)
+
+// A Registry holds the pipelines whose metrics are exported. It is safe to use from multiple goroutines.
+type Registry struct {
+	lock      sync.Mutex
+	pipelines []*jpipe.Pipeline
+}
+
+// NewRegistry returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a pipeline to the registry. Registering a pipeline twice has no effect.
+// Pipelines are identified by name in the series labels, so it returns ErrUnnamedPipeline if the pipeline has no name,
+// or ErrDuplicatePipelineName if another registered pipeline has the same name.
+// Pipelines are kept after they are done, so their final metrics can still be scraped, until they are unregistered.
+func (r *Registry) Register(pipeline *jpipe.Pipeline) error {
+	if pipeline.Name() == "" {
+		return ErrUnnamedPipeline
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, p := range r.pipelines {
+		if p == pipeline {
+			return nil
+		}
+		if p.Name() == pipeline.Name() {
+			return fmt.Errorf("%w: %q", ErrDuplicatePipelineName, pipeline.Name())
+		}
+	}
+	r.pipelines = append(r.pipelines, pipeline)
+	return nil
+}
+
+// Unregister removes a pipeline from the registry
+func (r *Registry) Unregister(pipeline *jpipe.Pipeline) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i, p := range r.pipelines {
+		if p == pipeline {
+			r.pipelines = append(r.pipelines[:i], r.pipelines[i+1:]...)
+			return
+		}
+	}
+}
+
+// WriteTo writes the metrics of all registered pipelines to w in the Prometheus text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	buffer, err := r.render()
+	if err != nil {
+		return 0, err
+	}
+	return buffer.WriteTo(w)
+}
+
+// ServeHTTP writes the metrics of all registered pipelines as an HTTP response, so a Registry can be scraped by Prometheus.
+// Metrics are rendered before writing the response, so a failure results in a 500 status instead of a partial response.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	buffer, err := r.render()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	buffer.WriteTo(w)
+}
+
+// render returns the metrics of all registered pipelines in the Prometheus text exposition format
+func (r *Registry) render() (*bytes.Buffer, error) {
+	r.lock.Lock()
+	pipelines := append([]*jpipe.Pipeline{}, r.pipelines...)
+	r.lock.Unlock()
+
+	families := newFamilies()
+	for _, pipeline := range pipelines {
+		pipelineLabels := labels{{"pipeline", pipeline.Name()}}
+		families.add("jpipe_pipeline_done", "gauge", "Whether the pipeline is done (1) or not (0).", pipelineLabels, boolValue(pipeline.IsDone()))
+		families.add("jpipe_pipeline_paused", "gauge", "Whether the pipeline is paused (1) or not (0).", pipelineLabels, boolValue(pipeline.Paused()))
+
+		for _, node := range pipeline.Stats().Nodes {
+			nodeLabels := labels{
+				{"pipeline", pipeline.Name()},
+				{"node_type", node.Type},
+				{"node_name", node.Name},
+				{"node_id", strconv.Itoa(node.ID)},
+			}
+			families.add("jpipe_node_items_received_total", "counter", "Number of values read by the operator from its inputs.", nodeLabels, float64(node.ItemsIn))
+			families.add("jpipe_node_items_sent_total", "counter", "Number of values sent by the operator to its outputs.", nodeLabels, float64(node.ItemsOut))
+			families.add("jpipe_node_send_blocked_seconds_total", "counter", "Time the operator spent sending values, i.e. under backpressure.", nodeLabels, node.SendBlocked.Seconds())
+			families.add("jpipe_node_input_wait_seconds_total", "counter", "Time the operator spent waiting for input values.", nodeLabels, node.InputWait.Seconds())
+			families.add("jpipe_node_buffer_capacity", "gauge", "Total capacity of the output buffers of the operator.", nodeLabels, float64(node.BufferSize))
+			families.add("jpipe_node_buffer_length", "gauge", "Number of values currently in the output buffers of the operator.", nodeLabels, float64(node.BufferLength))
+			families.addHistogram("jpipe_node_latency_seconds", "Duration of the calls to the operator function.", nodeLabels, node.Latency)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := families.write(&buffer); err != nil {
+		return nil, err
+	}
+	return &buffer, nil
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+type labels []label
+
+func (l labels) with(name string, value string) labels {
+	return append(append(labels{}, l...), label{name, value})
+}
+
+func (l labels) String() string {
+	parts := make([]string, len(l))
+	for i := range l {
+		parts[i] = fmt.Sprintf(`%s="%s"`, l[i].name, escapeLabelValue(l[i].value))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// family is a metric family, i.e. all the series of a metric, written together after its HELP and TYPE lines
+type family struct {
+	name       string
+	metricType string
+	help       string
+	lines      []string
+}
+
+// families keeps metric families in the order they are first added
+type families struct {
+	byName map[string]*family
+	order  []*family
+}
+
+func newFamilies() *families {
+	return &families{byName: map[string]*family{}}
+}
+
+func (f *families) get(name string, metricType string, help string) *family {
+	if fam, ok := f.byName[name]; ok {
+		return fam
+	}
+	fam := &family{name: name, metricType: metricType, help: help}
+	f.byName[name] = fam
+	f.order = append(f.order, fam)
+	return fam
+}
+
+func (f *families) add(name string, metricType string, help string, labels labels, value float64) {
+	fam := f.get(name, metricType, help)
+	fam.lines = append(fam.lines, name+labels.String()+" "+formatValue(value))
+}
+
+func (f *families) addHistogram(name string, help string, labels labels, histogram jpipe.LatencyHistogram) {
+	fam := f.get(name, "histogram", help)
+	cumulative := int64(0)
+	for _, bucket := range histogram.Buckets {
+		cumulative += bucket.Count
+		le := "+Inf"
+		if bucket.UpperBound != time.Duration(math.MaxInt64) {
+			le = formatValue(bucket.UpperBound.Seconds())
+		}
+		fam.lines = append(fam.lines, name+"_bucket"+labels.with("le", le).String()+" "+strconv.FormatInt(cumulative, 10))
+	}
+	fam.lines = append(fam.lines, name+"_sum"+labels.String()+" "+formatValue(histogram.Sum.Seconds()))
+	fam.lines = append(fam.lines, name+"_count"+labels.String()+" "+strconv.FormatInt(histogram.Count, 10))
+}
+
+func (f *families) write(w io.Writer) error {
+	for _, fam := range f.order {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", fam.name, fam.help, fam.name, fam.metricType); err != nil {
+			return err
+		}
+		for _, line := range fam.lines {
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,96 @@
+package metrics_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/junitechnology/jpipe"
+	"github.com/junitechnology/jpipe/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("Writes pipeline metrics in the text exposition format", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		pipeline := jpipe.NewPipeline(jpipe.Config{Name: "orders"})
+		registry.Register(pipeline)
+		<-jpipe.Map(jpipe.FromSlice(pipeline, []int{1, 2, 3}), func(i int) int { return i * 2 }, jpipe.Name("double")).ToSlice()
+		pipeline.Wait()
+
+		var buffer bytes.Buffer
+		_, err := registry.WriteTo(&buffer)
+		output := buffer.String()
+
+		assert.NoError(t, err)
+		mapLabels := `pipeline="orders",node_type="Map",node_name="double",node_id="1"`
+		assert.Contains(t, output, "# TYPE jpipe_pipeline_done gauge\njpipe_pipeline_done{pipeline=\"orders\"} 1\n")
+		assert.Contains(t, output, "# TYPE jpipe_node_items_received_total counter\n")
+		assert.Contains(t, output, "jpipe_node_items_received_total{"+mapLabels+"} 3\n")
+		assert.Contains(t, output, "jpipe_node_items_sent_total{"+mapLabels+"} 3\n")
+		assert.Contains(t, output, "jpipe_node_buffer_length{"+mapLabels+"} 0\n")
+		assert.Contains(t, output, "# TYPE jpipe_node_latency_seconds histogram\n")
+		assert.Contains(t, output, "jpipe_node_latency_seconds_bucket{"+mapLabels+",le=\"10\"} 3\n")
+		assert.Contains(t, output, "jpipe_node_latency_seconds_bucket{"+mapLabels+",le=\"+Inf\"} 3\n")
+		assert.Contains(t, output, "jpipe_node_latency_seconds_count{"+mapLabels+"} 3\n")
+		assert.Contains(t, output, `jpipe_node_items_sent_total{pipeline="orders",node_type="FromSlice",node_name="",node_id="0"} 3`)
+	})
+
+	t.Run("Escapes label values", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		pipeline := jpipe.NewPipeline(jpipe.Config{Name: "a \"quoted\"\nname", StartManually: true})
+		registry.Register(pipeline)
+
+		var buffer bytes.Buffer
+		registry.WriteTo(&buffer)
+
+		assert.Contains(t, buffer.String(), `jpipe_pipeline_done{pipeline="a \"quoted\"\nname"} 0`)
+	})
+
+	t.Run("Does not write unregistered pipelines", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		pipeline := jpipe.NewPipeline(jpipe.Config{Name: "orders", StartManually: true})
+		assert.NoError(t, registry.Register(pipeline))
+		assert.NoError(t, registry.Register(pipeline))
+		registry.Unregister(pipeline)
+
+		var buffer bytes.Buffer
+		registry.WriteTo(&buffer)
+
+		assert.Empty(t, buffer.String())
+	})
+
+	t.Run("Rejects unnamed pipelines", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+
+		err := registry.Register(pipeline)
+
+		assert.ErrorIs(t, err, metrics.ErrUnnamedPipeline)
+	})
+
+	t.Run("Rejects pipelines with duplicate names", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		pipeline1 := jpipe.NewPipeline(jpipe.Config{Name: "orders", StartManually: true})
+		pipeline2 := jpipe.NewPipeline(jpipe.Config{Name: "orders", StartManually: true})
+		assert.NoError(t, registry.Register(pipeline1))
+
+		err := registry.Register(pipeline2)
+
+		assert.ErrorIs(t, err, metrics.ErrDuplicatePipelineName)
+		registry.Unregister(pipeline1)
+		assert.NoError(t, registry.Register(pipeline2)) // the name is free again
+	})
+
+	t.Run("Serves metrics over HTTP", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		pipeline := jpipe.NewPipeline(jpipe.Config{Name: "orders", StartManually: true})
+		registry.Register(pipeline)
+		recorder := httptest.NewRecorder()
+
+		registry.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+		assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+		assert.Contains(t, recorder.Body.String(), `jpipe_pipeline_paused{pipeline="orders"} 0`)
+	})
+}
@@ -1,17 +1,25 @@
 package jpipe
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/junitechnology/jpipe/options"
 )
 
 type pipelineNode interface {
 	Start()
+	Abort()
+	Drain()
 	Done() <-chan struct{}
 	Children() []pipelineNode
+	GraphChildren() []pipelineNode
+	GraphNode() GraphNode
+	Stats() NodeStats
 	IsSource() bool
 	IsSink() bool
 }
@@ -19,26 +27,50 @@ type pipelineNode interface {
 type node[T any, R any] struct {
 	lock sync.Mutex
 
-	nodeType string
+	id          int
+	nodeType    string
+	name        string
+	concurrency int
+	bufferSize  int
+	itemsIn     atomic.Int64
+	itemsOut    atomic.Int64
+	latency     latencyHistogram
+	sendBlock   blockTracker
+	inputBlock  blockTracker
 
 	pipeline        *Pipeline
+	observer        Observer
+	logger          *slog.Logger
+	logEvery        int
+	valueLogLevel   slog.Level
 	inputs          []*Channel[T]
 	outputs         []*Channel[R]
 	outputWriters   []chan<- R
 	subscriptions   []chan struct{}
 	allUnsubscribed chan struct{}
 
-	worker     worker[T, R]
-	quitSignal chan struct{}
-	doneSignal chan struct{}
+	worker      worker[T, R]
+	ctx         context.Context
+	drainSignal chan struct{}
+	quitSignal  chan struct{}
+	doneSignal  chan struct{}
 }
 
 type workerNode[T any, R any] interface {
 	Inputs() []*Channel[T]
 	LoopInput(i int, function func(value T) bool)
+	Received(value T)
+	RecordLatency(latency time.Duration)
+	StartSending() time.Time
+	EndSending(start time.Time)
 	Send(value R) bool
+	SendTo(i int, value R) bool
 	QuitSignal() <-chan struct{}
+	DrainSignal() <-chan struct{}
+	PauseSignals() (paused <-chan struct{}, resumed <-chan struct{})
+	Context() context.Context
 	HandlePanic()
+	Cancel(err error)
 }
 
 func newPipelineNode[T any, R any](
@@ -51,19 +83,34 @@ func newPipelineNode[T any, R any](
 	opts ...options.NodeOption) (pipelineNode, []*Channel[R]) {
 
 	buffered := getOptionOrDefault(opts, Buffered(0))
+	name := getOptionOrDefault(opts, Name(""))
+	concurrent := getOptionOrDefault(opts, Concurrent(1))
+	logOpt := getOption[options.NodeOption, options.Log](opts)
+	id := pipeline.nextNodeID()
 
 	node := &node[T, R]{
+		id:              id,
 		nodeType:        nodeType,
+		name:            name.Name,
+		concurrency:     concurrent.Concurrency,
+		bufferSize:      buffered.Size,
 		pipeline:        pipeline,
+		observer:        pipeline.observer,
+		logger:          nodeLogger(pipeline, id, nodeType, name.Name, logOpt),
 		inputs:          inputs,
 		outputs:         make([]*Channel[R], numOutputs),
 		subscriptions:   make([]chan struct{}, numOutputs),
 		allUnsubscribed: make(chan struct{}),
 		worker:          worker,
+		drainSignal:     make(chan struct{}),
 		quitSignal:      make(chan struct{}),
 		doneSignal:      make(chan struct{}),
 	}
 
+	if logOpt != nil {
+		node.logEvery = logOpt.Every
+		node.valueLogLevel = valueLogLevel(pipeline)
+	}
 	for _, input := range inputs {
 		input.setToNode(node)
 	}
@@ -109,6 +156,12 @@ func newSinkPipelineNode[T any](nodeType string, input *Channel[T], worker worke
 }
 
 func (node *node[T, R]) Start() {
+	ctx, cancel := context.WithCancel(node.pipeline.Context())
+	if node.logger != nil {
+		ctx = contextWithLogger(ctx, node.logger)
+	}
+	node.ctx = ctx
+
 	go func() {
 		defer func() {
 			for i := range node.outputWriters {
@@ -117,6 +170,12 @@ func (node *node[T, R]) Start() {
 			for i := range node.inputs {
 				node.inputs[i].unsubscribe()
 			}
+			if node.observer != nil {
+				node.observer.OnNodeDone(node.info())
+			}
+			if node.logger != nil {
+				node.logger.Debug("operator done")
+			}
 			close(node.doneSignal)
 		}()
 
@@ -129,17 +188,58 @@ func (node *node[T, R]) Start() {
 				}
 			}
 			close(node.quitSignal)
+			cancel()
 		}()
 
 		defer node.HandlePanic()
+		if node.observer != nil {
+			node.observer.OnNodeStart(node.info())
+		}
+		if node.logger != nil {
+			node.logger.Debug("operator started")
+		}
 		node.worker(node)
 	}()
 }
 
+// Abort finishes a node that will never be started, closing its outputs so anything reading them finishes too
+func (node *node[T, R]) Abort() {
+	for i := range node.outputWriters {
+		close(node.outputWriters[i])
+	}
+	close(node.doneSignal)
+}
+
 func (node *node[T, R]) QuitSignal() <-chan struct{} {
 	return node.quitSignal
 }
 
+// Drain signals a source node to stop producing values, so downstream nodes can finish processing the ones in flight
+func (node *node[T, R]) Drain() {
+	node.lock.Lock()
+	defer node.lock.Unlock()
+
+	select {
+	case <-node.drainSignal: // for idempotency
+	default:
+		close(node.drainSignal)
+	}
+}
+
+func (node *node[T, R]) DrainSignal() <-chan struct{} {
+	return node.drainSignal
+}
+
+// PauseSignals returns a channel that's closed while the pipeline is paused, and another one that's closed while it is not
+func (node *node[T, R]) PauseSignals() (<-chan struct{}, <-chan struct{}) {
+	return node.pipeline.pauseSignals()
+}
+
+// Context returns a context that's canceled when the node quits
+func (node *node[T, R]) Context() context.Context {
+	return node.ctx
+}
+
 func (node *node[T, R]) Done() <-chan struct{} {
 	return node.doneSignal
 }
@@ -153,6 +253,52 @@ func (node *node[T, R]) Children() []pipelineNode {
 	return children
 }
 
+// GraphChildren works like Children, but it also returns the nodes only shown as reading from the outputs in the pipeline graph
+func (node *node[T, R]) GraphChildren() []pipelineNode {
+	children := make([]pipelineNode, len(node.outputs))
+	for i := range node.outputs {
+		children[i] = node.outputs[i].getGraphToNode()
+	}
+
+	return children
+}
+
+func (node *node[T, R]) GraphNode() GraphNode {
+	return GraphNode{
+		ID:          node.id,
+		Type:        node.nodeType,
+		Name:        node.name,
+		Concurrency: node.concurrency,
+		BufferSize:  node.bufferSize,
+		Inputs:      len(node.inputs),
+		Outputs:     len(node.outputs),
+		ItemsIn:     node.itemsIn.Load(),
+		ItemsOut:    node.itemsOut.Load(),
+	}
+}
+
+func (node *node[T, R]) Stats() NodeStats {
+	bufferLength := 0
+	for _, writer := range node.outputWriters {
+		bufferLength += len(writer)
+	}
+
+	return NodeStats{
+		ID:           node.id,
+		Type:         node.nodeType,
+		Name:         node.name,
+		ItemsIn:      node.itemsIn.Load(),
+		ItemsOut:     node.itemsOut.Load(),
+		Latency:      node.latency.snapshot(),
+		SendBlocked:  node.sendBlock.total(),
+		SendingSince: node.sendBlock.blockedSince(),
+		InputWait:    node.inputBlock.total(),
+		WaitingSince: node.inputBlock.blockedSince(),
+		BufferSize:   node.bufferSize * len(node.outputWriters),
+		BufferLength: bufferLength,
+	}
+}
+
 func (node *node[T, R]) IsSource() bool {
 	return len(node.inputs) == 0
 }
@@ -166,52 +312,180 @@ func (node *node[T, R]) Inputs() []*Channel[T] {
 }
 
 func (node *node[T, R]) LoopInput(i int, function func(value T) bool) {
-	loopOverChannel[T, T, R](node, node.inputs[i].getChannel(), function)
+	channel := node.inputs[i].getChannel()
+	for {
+		select { // the nested select gives priority to the quit signal, so we always exit early if needed
+		case <-node.quitSignal:
+			return
+		default:
+		}
+
+		var value T
+		var open bool
+		select {
+		case value, open = <-channel: // the wait is only tracked if there's no value ready, which keeps the common case cheap
+		default:
+			waitStart := node.inputBlock.start()
+			select {
+			case <-node.quitSignal:
+				node.inputBlock.end(waitStart)
+				return
+			case value, open = <-channel:
+				node.inputBlock.end(waitStart)
+			}
+		}
+
+		if !open {
+			return
+		}
+		node.Received(value)
+		if !function(value) {
+			return
+		}
+	}
+}
+
+// RecordLatency records the duration of a call to the operator function
+func (node *node[T, R]) RecordLatency(latency time.Duration) {
+	node.latency.record(latency)
+}
+
+// Received records that a value was read from an input.
+// LoopInput already calls it, so only workers reading their inputs directly must call it.
+func (node *node[T, R]) Received(value T) {
+	count := node.itemsIn.Add(1)
+	if node.isLogSampled(count) {
+		node.logger.Log(context.Background(), node.valueLogLevel, "value received", "value", value, "count", count)
+	}
+	if node.observer != nil {
+		node.observer.OnItemReceived(node.info(), value)
+	}
+}
+
+// sent records that a value was sent to the outputs
+func (node *node[T, R]) sent(value R) {
+	node.itemsOut.Add(1)
+	if node.observer != nil {
+		node.observer.OnItemSent(node.info(), value)
+	}
+}
+
+// isLogSampled returns whether the count-th value must be logged, i.e. the node has the Log option and the value is sampled
+func (node *node[T, R]) isLogSampled(count int64) bool {
+	return node.logEvery > 0 && (count-1)%int64(node.logEvery) == 0
+}
+
+func (node *node[T, R]) info() NodeInfo {
+	return NodeInfo{ID: node.id, Type: node.nodeType, Name: node.name}
 }
 
 func (node *node[T, R]) HandlePanic() {
 	if r := recover(); r != nil {
-		node.pipeline.Cancel(fmt.Errorf("%v: %s", r, debug.Stack()))
+		panicErr, ok := r.(*PanicError)
+		if !ok { // panics forwarded from other goroutines are already a *PanicError with the original stack
+			panicErr = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+		panicErr.NodeType = node.nodeType
+		panicErr.NodeID = node.id
+		if node.observer != nil {
+			node.observer.OnPanic(node.info(), panicErr)
+		}
+		if node.logger != nil {
+			node.logger.Error("operator panicked", "panic", panicErr.Value, "stack", string(panicErr.Stack))
+		}
+		node.pipeline.Cancel(node.stageError(panicErr))
+	}
+}
+
+// Cancel cancels the pipeline with an error coming from the operator function.
+// Errors are ignored if the node is already quitting, as they are most likely caused by the quitting itself.
+func (node *node[T, R]) Cancel(err error) {
+	select {
+	case <-node.quitSignal:
+	default:
+		node.pipeline.Cancel(node.stageError(err))
 	}
 }
 
+func (node *node[T, R]) stageError(err error) *StageError {
+	return &StageError{NodeType: node.nodeType, NodeName: node.name, Index: node.id, Err: err}
+}
+
 func (node *node[T, R]) Send(value R) bool {
 	// handle shared output case
 	if len(node.outputWriters) == 1 && len(node.outputs) > 1 {
-		select {
-		case <-node.quitSignal:
-			return false
-		default:
-			select {
-			case <-node.quitSignal:
-				return false
-			case <-node.allUnsubscribed:
-				return false
-			case node.outputWriters[0] <- value:
-				return true
-			}
+		sent, _ := node.send(node.outputWriters[0], value, node.allUnsubscribed)
+		if sent {
+			node.sent(value)
 		}
+		return sent
 	}
 
 	success := false
 	for i := range node.outputs {
-		select {
-		case <-node.quitSignal:
-			return false // the nested select gives priority to the quit signal, so we always exit early if needed
-		default:
-			select {
-			case <-node.quitSignal:
-				return false
-			case node.outputWriters[i] <- value:
-				success = true // we return true if we sent to at least one subscriber. If we don't it means there's no active subscriber left.
-			case <-node.subscriptions[i]: // do nothing if subscription is canceled
-			}
+		sent, quit := node.send(node.outputWriters[i], value, node.subscriptions[i])
+		if quit {
+			return false
 		}
+		success = success || sent // we return true if we sent to at least one subscriber. If we don't it means there's no active subscriber left.
 	}
 
+	if success {
+		node.sent(value)
+	}
 	return success
 }
 
+// SendTo sends a value only to the i-th output.
+// The value is silently dropped if that output was unsubscribed, so it only returns false if the node must quit.
+func (node *node[T, R]) SendTo(i int, value R) bool {
+	sent, quit := node.send(node.outputWriters[i], value, node.subscriptions[i])
+	if sent {
+		node.sent(value)
+	}
+	return !quit
+}
+
+// send sends a value to an output writer, unless unsubscribed is closed first.
+// quit is true if it returned early because the node must quit.
+// The time blocked sending is only tracked if the value can't be sent right away, which keeps the common case cheap.
+func (node *node[T, R]) send(writer chan<- R, value R, unsubscribed <-chan struct{}) (sent bool, quit bool) {
+	select { // the nested selects give priority to the quit signal, so we always exit early if needed
+	case <-node.quitSignal:
+		return false, true
+	default:
+	}
+
+	select {
+	case writer <- value:
+		return true, false
+	case <-unsubscribed:
+		return false, false
+	default:
+	}
+
+	defer node.EndSending(node.StartSending())
+	select {
+	case <-node.quitSignal:
+		return false, true
+	case writer <- value:
+		return true, false
+	case <-unsubscribed:
+		return false, false
+	}
+}
+
+// StartSending records that a worker started sending a value, and returns the start time to be passed to EndSending.
+// Send and SendTo already call it, so only workers sending values elsewhere must call it, e.g. ToGoChannel.
+func (node *node[T, R]) StartSending() time.Time {
+	return node.sendBlock.start()
+}
+
+// EndSending records that a worker finished sending a value
+func (node *node[T, R]) EndSending(start time.Time) {
+	node.sendBlock.end(start)
+}
+
 func (node *node[T, R]) unsubscribe(n int) {
 	node.lock.Lock()
 	defer node.lock.Unlock()
@@ -0,0 +1,45 @@
+package jpipe
+
+// An Observer receives lifecycle events from a pipeline and its operators, e.g. to plug logging, tracing or metrics in one place.
+// It is set with [Config].Observer. Callbacks are called synchronously from the goroutines of the pipeline,
+// so they must be safe for concurrent use, and they should be fast, as they slow down the pipeline otherwise.
+// Embed [BaseObserver] to implement only some of the callbacks.
+type Observer interface {
+	// OnPipelineStart is called when the pipeline starts, before any operator starts
+	OnPipelineStart(pipeline *Pipeline)
+	// OnNodeStart is called when an operator starts
+	OnNodeStart(node NodeInfo)
+	// OnItemReceived is called every time an operator reads a value from an input
+	OnItemReceived(node NodeInfo, value any)
+	// OnItemSent is called every time an operator sends a value to its outputs
+	OnItemSent(node NodeInfo, value any)
+	// OnNodeDone is called when an operator exits
+	OnNodeDone(node NodeInfo)
+	// OnPanic is called when an operator panics, before the pipeline is canceled
+	OnPanic(node NodeInfo, err *PanicError)
+	// OnCancel is called once when the pipeline is done, with the pipeline error, which is nil if it completed successfully.
+	// It is called before [Pipeline.Done] is closed, so it has been called by the time Wait returns.
+	OnCancel(err error)
+}
+
+// NodeInfo identifies the operator an Observer event comes from
+type NodeInfo struct {
+	// ID identifies the operator within its pipeline, as in [GraphNode]
+	ID int
+	// Type is the type of the operator, e.g. "Map"
+	Type string
+	// Name is the name set with the Name option, if any
+	Name string
+}
+
+// BaseObserver implements Observer with no-op callbacks.
+// It is meant to be embedded in Observer implementations only interested in some events.
+type BaseObserver struct{}
+
+func (BaseObserver) OnPipelineStart(pipeline *Pipeline)      {}
+func (BaseObserver) OnNodeStart(node NodeInfo)               {}
+func (BaseObserver) OnItemReceived(node NodeInfo, value any) {}
+func (BaseObserver) OnItemSent(node NodeInfo, value any)     {}
+func (BaseObserver) OnNodeDone(node NodeInfo)                {}
+func (BaseObserver) OnPanic(node NodeInfo, err *PanicError)  {}
+func (BaseObserver) OnCancel(err error)                      {}
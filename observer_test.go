@@ -0,0 +1,94 @@
+package jpipe_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junitechnology/jpipe"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingObserver struct {
+	jpipe.BaseObserver
+	lock   sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) record(format string, args ...any) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+}
+
+func (o *recordingObserver) getEvents() []string {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return append([]string{}, o.events...)
+}
+
+func (o *recordingObserver) OnPipelineStart(pipeline *jpipe.Pipeline) { o.record("pipeline start") }
+func (o *recordingObserver) OnNodeStart(node jpipe.NodeInfo)          { o.record("start %s", node.Type) }
+func (o *recordingObserver) OnNodeDone(node jpipe.NodeInfo)           { o.record("done %s", node.Type) }
+func (o *recordingObserver) OnCancel(err error)                       { o.record("cancel %v", err) }
+
+func (o *recordingObserver) OnItemReceived(node jpipe.NodeInfo, value any) {
+	o.record("received %s %v", node.Type, value)
+}
+
+func (o *recordingObserver) OnItemSent(node jpipe.NodeInfo, value any) {
+	o.record("sent %s %v", node.Type, value)
+}
+
+func (o *recordingObserver) OnPanic(node jpipe.NodeInfo, err *jpipe.PanicError) {
+	o.record("panic %s %v", node.Type, err.Value)
+}
+
+func TestObserver(t *testing.T) {
+	t.Run("Receives lifecycle and item events", func(t *testing.T) {
+		observer := &recordingObserver{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Observer: observer})
+		channel := jpipe.Map(jpipe.FromSlice(pipeline, []int{1, 2}), func(i int) int { return i * 10 })
+		<-channel.ToSlice()
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+
+		events := observer.getEvents()
+		assert.Equal(t, "pipeline start", events[0])
+		assert.Equal(t, "cancel <nil>", events[len(events)-1])
+		assert.ElementsMatch(t, []string{
+			"pipeline start",
+			"start FromSlice", "start Map", "start ToSlice",
+			"sent FromSlice 1", "sent FromSlice 2",
+			"received Map 1", "received Map 2",
+			"sent Map 10", "sent Map 20",
+			"received ToSlice 10", "received ToSlice 20",
+			"done FromSlice", "done Map", "done ToSlice",
+			"cancel <nil>",
+		}, events)
+		assert.Less(t, indexOf(events, "received Map 1"), indexOf(events, "sent Map 10"))
+	})
+
+	t.Run("Receives panics and the pipeline error", func(t *testing.T) {
+		observer := &recordingObserver{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Context: context.TODO(), Observer: observer})
+		jpipe.FromSlice(pipeline, []int{1}).ForEach(func(i int) { panic("boom") })
+		<-pipeline.Done()
+
+		assert.Contains(t, observer.getEvents(), "panic ForEach boom")
+		var panicErr *jpipe.PanicError
+		assert.True(t, errors.As(pipeline.Error(), &panicErr))
+		assert.Contains(t, observer.getEvents(), "cancel "+pipeline.Error().Error()) // OnCancel is called before the pipeline is done
+	})
+}
+
+func indexOf(events []string, event string) int {
+	for i := range events {
+		if events[i] == event {
+			return i
+		}
+	}
+	return -1
+}
@@ -1,6 +1,12 @@
 package jpipe
 
-import "github.com/junitechnology/jpipe/options"
+import (
+	"context"
+	"time"
+
+	"github.com/junitechnology/jpipe/options"
+	"github.com/junitechnology/jpipe/tracing"
+)
 
 func Concurrent(concurrency int) options.Concurrent {
 	return options.Concurrent{Concurrency: concurrency}
@@ -14,6 +20,11 @@ func Buffered(size int) options.Buffered {
 	return options.Buffered{Size: size}
 }
 
+// Name sets a name for an operator, so it can be identified in errors, metrics and graph exports
+func Name(name string) options.Name {
+	return options.Name{Name: name}
+}
+
 func KeepFirst() options.Keep {
 	return options.Keep{Strategy: options.KEEP_FIRST}
 }
@@ -22,6 +33,108 @@ func KeepLast() options.Keep {
 	return options.Keep{Strategy: options.KEEP_LAST}
 }
 
+// Timeout limits the duration of every call to an operator function.
+// Functions receiving a context get one that expires after the timeout.
+// When the timeout expires, the call is abandoned and ErrTimeout is handled like any other error returned by the function,
+// so it cancels the pipeline unless the operator has an error strategy that says otherwise.
+// The operator moves on to the next value, but an abandoned call keeps running in its own goroutine until the function returns,
+// so functions should still return as soon as their context is done.
+// When combined with Retry, the timeout applies to every attempt.
+func Timeout(timeout time.Duration) options.Timeout {
+	return options.Timeout{Duration: timeout}
+}
+
+// Retry makes an operator call its function up to maxAttempts times while it returns an error.
+// Attempts are separated by an exponential backoff starting at 100ms, doubling on every attempt up to 10s, with a 20% jitter.
+// The backoff and the errors to retry can be customized with the options.Retry methods.
+// Panics are retried too, as *PanicError errors, so it also applies to operators whose function can't return an error, e.g. Map.
+// If the last attempt panics, the panic is handled as usual. Retries are interrupted as soon as the operator quits.
+func Retry(maxAttempts int) options.Retry {
+	return options.Retry{
+		MaxAttempts:  maxAttempts,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     10 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+}
+
+// ForwardErrors makes item operators send errored items downstream untouched.
+// This is the default for non-sink item operators like MapItems.
+func ForwardErrors() options.OnError {
+	return options.OnError{Strategy: options.FORWARD_ERRORS}
+}
+
+// FailFast makes item operators cancel the pipeline with the first error found.
+// This is the default for item sinks like ForEachItems.
+func FailFast() options.OnError {
+	return options.OnError{Strategy: options.FAIL_FAST}
+}
+
+// SkipErrors makes item operators silently drop errored items.
+func SkipErrors() options.OnError {
+	return options.OnError{Strategy: options.SKIP_ERRORS}
+}
+
+// CollectErrors makes item sinks like ForEachItems join all errors found and send them through the returned channel.
+// It can only be used with item sinks. Non-sink item operators panic with it.
+func CollectErrors() options.OnError {
+	return options.OnError{Strategy: options.COLLECT_ERRORS}
+}
+
+// Prefetch makes FromPages fetch up to depth pages ahead of the one being sent, while values are still being sent downstream.
+// A depth of 0 disables prefetching, so every page is fetched only once all values of the previous one have been sent.
+func Prefetch(depth int) options.Prefetch {
+	return options.Prefetch{Depth: depth}
+}
+
+// CSV sets the format of CSV sources and sinks like FromCSV and ToCSV. By default, fields are delimited by ',' and there is no header.
+// It can be customized with the options.CSV methods.
+func CSV() options.CSV {
+	return options.CSV{Comma: ','}
+}
+
+// FlushEvery makes writer sinks like ToWriter flush their buffered output after every count values, and every interval.
+// Either of them can be 0 to disable it. By default, output is only flushed when the buffer is full and when the sink exits.
+func FlushEvery(count int, interval time.Duration) options.FlushEvery {
+	return options.FlushEvery{Count: count, Interval: interval}
+}
+
+// Counters makes graph exports like Pipeline.ExportMermaid include the number of values read and sent by every operator so far
+func Counters() options.Counters {
+	return options.Counters{}
+}
+
+// Traced makes item operators like MapItems start a span with the tracer for every item.
+// The span is a child of the span in the item context, if any, and the item continues downstream with the context of the span.
+// Errored items and function errors are recorded in the span. The span is named after the operator name, or its type if it has no name.
+func Traced(tracer tracing.Tracer) options.Traced {
+	return options.Traced{Start: func(ctx context.Context, name string) (context.Context, func(err error)) {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, span := tracer.Start(ctx, name)
+		return ctx, func(err error) {
+			if err != nil {
+				span.RecordError(err)
+			}
+			span.End()
+		}
+	}}
+}
+
+// Log makes an operator log every value it reads at Debug level with the logger in the pipeline Config.
+// If there is none, values are logged at Info level with slog.Default(), whose default handler drops Debug records.
+// It also makes the operator log its lifecycle events, as all operators do when the pipeline has a logger.
+func Log() options.Log {
+	return options.Log{Every: 1}
+}
+
+// LogEvery is like Log, but it only logs one of every n values, so busy operators don't flood the logs
+func LogEvery(n int) options.Log {
+	return options.Log{Every: n}
+}
+
 func getOption[I any, O any](opts []I) *O {
 	for i := range opts {
 		if opt, ok := any(opts[i]).(O); ok {
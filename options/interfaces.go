@@ -39,3 +39,51 @@ type FilterOption interface {
 type TapOption interface {
 	isTapOption()
 }
+
+type MapItemsOption interface {
+	isMapItemsOption()
+}
+
+type ForEachItemsOption interface {
+	isForEachItemsOption()
+}
+
+type DeadLetterOption interface {
+	isDeadLetterOption()
+}
+
+type GeneratorOption interface {
+	isGeneratorOption()
+}
+
+type FromPagesOption interface {
+	isFromPagesOption()
+}
+
+type ReaderOption interface {
+	isReaderOption()
+}
+
+type CSVOption interface {
+	isCSVOption()
+}
+
+type WriterOption interface {
+	isWriterOption()
+}
+
+type CSVWriterOption interface {
+	isCSVWriterOption()
+}
+
+type ExportOption interface {
+	isExportOption()
+}
+
+type UnfoldOption interface {
+	isUnfoldOption()
+}
+
+type DiscardOption interface {
+	isDiscardOption()
+}
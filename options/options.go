@@ -1,15 +1,24 @@
 package options
 
+import (
+	"context"
+	"time"
+)
+
 type Concurrent struct {
 	Concurrency int
 }
 
+func (c Concurrent) isNodeOption()         {}
 func (c Concurrent) isPooledWorkerOption() {}
 func (c Concurrent) isForEachOption()      {}
 func (c Concurrent) isMapOption()          {}
 func (c Concurrent) isFlatMapOption()      {}
 func (c Concurrent) isFilterOption()       {}
 func (c Concurrent) isTapOption()          {}
+func (c Concurrent) isMapItemsOption()     {}
+func (c Concurrent) isForEachItemsOption() {}
+func (c Concurrent) isGeneratorOption()    {}
 
 type Ordered struct {
 	OrderBufferSize int
@@ -20,14 +29,16 @@ func (o Ordered) isMapOption()          {}
 func (o Ordered) isFlatMapOption()      {}
 func (o Ordered) isFilterOption()       {}
 func (o Ordered) isTapOption()          {}
+func (o Ordered) isMapItemsOption()     {}
 
 type Buffered struct {
 	Size int
 }
 
-func (b Buffered) isNodeOption()      {}
-func (b Buffered) isSplitOption()     {}
-func (b Buffered) isBroadcastOption() {}
+func (b Buffered) isNodeOption()       {}
+func (b Buffered) isSplitOption()      {}
+func (b Buffered) isBroadcastOption()  {}
+func (b Buffered) isDeadLetterOption() {}
 
 type Keep struct {
 	Strategy KeepStrategy
@@ -41,3 +52,167 @@ const (
 )
 
 func (k Keep) isToMapOption() {}
+
+type OnError struct {
+	Strategy ErrorStrategy
+}
+
+type ErrorStrategy string
+
+const (
+	FORWARD_ERRORS ErrorStrategy = "FORWARD_ERRORS"
+	FAIL_FAST      ErrorStrategy = "FAIL_FAST"
+	SKIP_ERRORS    ErrorStrategy = "SKIP_ERRORS"
+	COLLECT_ERRORS ErrorStrategy = "COLLECT_ERRORS"
+)
+
+func (o OnError) isMapItemsOption()     {}
+func (o OnError) isForEachItemsOption() {}
+
+type Timeout struct {
+	Duration time.Duration
+}
+
+func (t Timeout) isPooledWorkerOption() {}
+func (t Timeout) isForEachOption()      {}
+func (t Timeout) isMapOption()          {}
+func (t Timeout) isFilterOption()       {}
+func (t Timeout) isTapOption()          {}
+func (t Timeout) isMapItemsOption()     {}
+func (t Timeout) isForEachItemsOption() {}
+
+type Retry struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	Jitter       float64
+	Retryable    func(error) bool
+}
+
+// WithBackoff sets the delay before the first retry, which is then multiplied by multiplier on every attempt, up to maxDelay
+func (r Retry) WithBackoff(initialDelay time.Duration, maxDelay time.Duration, multiplier float64) Retry {
+	r.InitialDelay = initialDelay
+	r.MaxDelay = maxDelay
+	r.Multiplier = multiplier
+	return r
+}
+
+// WithJitter sets the maximum fraction of every delay that is randomly added or subtracted to it.
+// It is clamped to the [0, 1] range, so delays are never negative.
+func (r Retry) WithJitter(jitter float64) Retry {
+	r.Jitter = min(max(jitter, 0), 1)
+	return r
+}
+
+// If sets a predicate deciding whether an error must be retried. All errors are retried if not set.
+func (r Retry) If(retryable func(error) bool) Retry {
+	r.Retryable = retryable
+	return r
+}
+
+func (r Retry) isPooledWorkerOption() {}
+func (r Retry) isForEachOption()      {}
+func (r Retry) isMapOption()          {}
+func (r Retry) isFilterOption()       {}
+func (r Retry) isTapOption()          {}
+func (r Retry) isMapItemsOption()     {}
+func (r Retry) isForEachItemsOption() {}
+
+type Name struct {
+	Name string
+}
+
+func (n Name) isNodeOption()         {}
+func (n Name) isForEachOption()      {}
+func (n Name) isMapOption()          {}
+func (n Name) isFlatMapOption()      {}
+func (n Name) isSplitOption()        {}
+func (n Name) isBroadcastOption()    {}
+func (n Name) isToMapOption()        {}
+func (n Name) isFilterOption()       {}
+func (n Name) isTapOption()          {}
+func (n Name) isMapItemsOption()     {}
+func (n Name) isForEachItemsOption() {}
+func (n Name) isDeadLetterOption()   {}
+func (n Name) isGeneratorOption()    {}
+func (n Name) isUnfoldOption()       {}
+func (n Name) isFromPagesOption()    {}
+func (n Name) isReaderOption()       {}
+func (n Name) isCSVOption()          {}
+func (n Name) isWriterOption()       {}
+func (n Name) isCSVWriterOption()    {}
+func (n Name) isDiscardOption()      {}
+
+type Prefetch struct {
+	Depth int
+}
+
+func (p Prefetch) isFromPagesOption() {}
+
+type CSV struct {
+	Comma   rune
+	Comment rune
+	Header  bool
+}
+
+// WithComma sets the field delimiter, which is ',' by default
+func (c CSV) WithComma(comma rune) CSV {
+	c.Comma = comma
+	return c
+}
+
+// WithComment sets the character starting comment lines, which are ignored. There are no comments by default
+func (c CSV) WithComment(comment rune) CSV {
+	c.Comment = comment
+	return c
+}
+
+// WithHeader makes the first record a header. Struct fields are matched to columns by name instead of by position.
+// Writers write a header with the column names of the struct fields
+func (c CSV) WithHeader() CSV {
+	c.Header = true
+	return c
+}
+
+func (c CSV) isCSVOption()       {}
+func (c CSV) isCSVWriterOption() {}
+
+type FlushEvery struct {
+	Count    int
+	Interval time.Duration
+}
+
+func (f FlushEvery) isWriterOption()    {}
+func (f FlushEvery) isCSVWriterOption() {}
+
+type Counters struct{}
+
+func (c Counters) isExportOption() {}
+
+type Traced struct {
+	// Start starts a span with the given name, as a child of the span in ctx, if any.
+	// It returns a context containing the new span, and a function ending it, recording the error if not nil.
+	Start func(ctx context.Context, name string) (context.Context, func(err error))
+}
+
+func (t Traced) isMapItemsOption()     {}
+func (t Traced) isForEachItemsOption() {}
+
+type Log struct {
+	Every int
+}
+
+func (l Log) isNodeOption()         {}
+func (l Log) isForEachOption()      {}
+func (l Log) isMapOption()          {}
+func (l Log) isFlatMapOption()      {}
+func (l Log) isSplitOption()        {}
+func (l Log) isBroadcastOption()    {}
+func (l Log) isToMapOption()        {}
+func (l Log) isFilterOption()       {}
+func (l Log) isTapOption()          {}
+func (l Log) isMapItemsOption()     {}
+func (l Log) isForEachItemsOption() {}
+func (l Log) isDeadLetterOption()   {}
+func (l Log) isDiscardOption()      {}
@@ -2,7 +2,10 @@ package jpipe
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"sync"
+	"time"
 )
 
 // A Pipeline is a container for the classic [Pipelines and Cancellation] pattern.
@@ -15,19 +18,36 @@ import (
 type Pipeline struct {
 	lock sync.Mutex
 
+	parentContext context.Context
 	context       context.Context
+	cancelContext context.CancelCauseFunc
 	startManually bool
+	observer      Observer
+	logger        *slog.Logger
+	name          string
+	watchdog      Watchdog
+	validate      bool
 
-	started bool
-	done    chan struct{}
-	err     error
+	started   bool
+	canceling bool          // set when Cancel is first called, before done is closed
+	paused    chan struct{} // closed while the pipeline is paused
+	resumed   chan struct{} // closed while the pipeline is not paused
+	resumedAt time.Time     // the last time the pipeline was resumed, if ever
+	done      chan struct{}
+	nodesDone chan struct{}
+	err       error
+	startErr  error // the error that prevented the pipeline from starting, if any
 
-	nodes       []pipelineNode
-	activeNodes sync.WaitGroup
+	nodes        []pipelineNode
+	dynamicNodes []pipelineNode // nodes created after the pipeline started, only while they are running
+	nodeCount    int
+	activeNodes  sync.WaitGroup
 }
 
 // A Config can be used to create a pipeline with certain settings
 type Config struct {
+	// Name identifies the pipeline, e.g. in metrics
+	Name string
 	// Context is used by a Pipeline for cancellation.
 	// If the context gets cancelled, the pipeline gets canceled too.
 	Context context.Context
@@ -35,6 +55,21 @@ type Config struct {
 	// If false, the first sink operator(ForEach, ToSlice, etc) to be created in the pipeline automatically starts it.
 	// If true, the pipeline will be dormant until [Pipeline.Start] is called.
 	StartManually bool
+	// Observer receives the lifecycle events of the pipeline and its operators.
+	// If nil, events are not tracked at all, so they have no overhead.
+	Observer Observer
+	// Logger is used to log the lifecycle of the pipeline and its operators, i.e. start and stop, cancellation cause, panics,
+	// retries and errored items dropped by the SkipErrors strategy. Every record has the pipeline name, and operator type, name and ID.
+	// If nil, nothing is logged, except for operators with the Log option, which use slog.Default().
+	Logger *slog.Logger
+	// Watchdog detects operators that have been blocked for too long, which usually means a Channel is not being read.
+	// It is disabled by default.
+	Watchdog Watchdog
+	// Validate makes the pipeline check on start that every Channel is consumed, as an unconsumed Channel eventually blocks it forever.
+	// If validation fails, no operator is started and the pipeline is canceled with the error returned by [Pipeline.Validate],
+	// which is also returned by [Pipeline.StartErr] and [Pipeline.Wait].
+	// With auto-start, validation happens as soon as the first sink is created, so pipelines with multiple sinks should set StartManually.
+	Validate bool
 }
 
 // New returns a pipeline with the given backing context.
@@ -46,14 +81,26 @@ func New(ctx context.Context) *Pipeline {
 // NewPipeline returns a Pipeline with the given [jpipe.Config]
 func NewPipeline(config Config) *Pipeline {
 	pipeline := Pipeline{
+		paused:        make(chan struct{}),
+		resumed:       make(chan struct{}),
 		done:          make(chan struct{}),
+		nodesDone:     make(chan struct{}),
 		startManually: config.StartManually,
+		observer:      config.Observer,
+		name:          config.Name,
+		watchdog:      config.Watchdog,
+		validate:      config.Validate,
 	}
 
 	if config.Context != nil {
-		pipeline.context = config.Context
+		pipeline.parentContext = config.Context
 	} else {
-		pipeline.context = context.TODO()
+		pipeline.parentContext = context.TODO()
+	}
+	pipeline.context, pipeline.cancelContext = context.WithCancelCause(pipeline.parentContext)
+	close(pipeline.resumed)
+	if config.Logger != nil {
+		pipeline.logger = withPipelineAttrs(config.Logger, config.Name)
 	}
 
 	return &pipeline
@@ -61,21 +108,36 @@ func NewPipeline(config Config) *Pipeline {
 
 // Start manually starts the pipeline.
 // If the pipeline is already started, Start has no effect.
+// If Validate is set in the pipeline's Config and some Channel is not consumed, no operator is started,
+// and the pipeline is canceled with the validation error, which is also returned by StartErr.
 func (p *Pipeline) Start() {
-	p.lock.Lock()
-	defer p.lock.Unlock()
-	if p.started {
+	if !p.markStarted() {
 		return
 	}
-	p.started = true
+
+	if p.validate {
+		if err := p.Validate(); err != nil {
+			p.abort(err)
+			return
+		}
+	}
+	if p.observer != nil {
+		p.observer.OnPipelineStart(p)
+	}
+	if p.logger != nil {
+		p.logger.Debug("pipeline started")
+	}
 
 	go func() {
 		select {
-		case <-p.context.Done():
-			p.Cancel(p.context.Err())
+		case <-p.parentContext.Done():
+			p.Cancel(contextError(p.parentContext))
 		case <-p.done:
 		}
 	}()
+	if p.watchdog.Threshold > 0 {
+		go p.watch(p.watchdog)
+	}
 
 	for _, node := range p.nodes {
 		node.Start()
@@ -89,27 +151,207 @@ func (p *Pipeline) Start() {
 	go func() {
 		p.activeNodes.Wait()
 		p.Cancel(nil)
+		close(p.nodesDone)
 	}()
 }
 
+// markStarted sets the pipeline as started, and returns false if it already was.
+// The lock is not held afterwards, as nodes are not added to p.nodes once started, and observers may call pipeline methods.
+func (p *Pipeline) markStarted() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.started {
+		return false
+	}
+	p.started = true
+	return true
+}
+
+// abort cancels a pipeline that failed to start, finishing its nodes without starting them
+func (p *Pipeline) abort(err error) {
+	p.lock.Lock()
+	p.startErr = err
+	p.lock.Unlock()
+
+	p.Cancel(err)
+	for _, node := range p.nodes {
+		node.Abort()
+	}
+	close(p.nodesDone)
+}
+
+// StartErr returns the error that prevented the pipeline from starting, i.e. the validation error if Validate is set in its Config.
+// It returns nil if the pipeline started, or hasn't been started yet.
+func (p *Pipeline) StartErr() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.startErr
+}
+
 // Cancel manually cancels the pipeline with the given error
 func (p *Pipeline) Cancel(err error) {
 	p.lock.Lock()
-	defer p.lock.Unlock()
-	if !p.IsDone() { // no further action needed if the pipeline was already done
-		if err != nil { // the if condition avoids a race condition when accessing Error()
-			p.err = err
+	if p.canceling { // no further action needed if the pipeline was already canceled
+		p.lock.Unlock()
+		return
+	}
+	p.canceling = true
+	if err != nil { // the if condition avoids a race condition when accessing Error()
+		p.err = err
+	}
+	p.lock.Unlock()
+
+	// the observer is notified before closing done, so anyone waiting for the pipeline sees the observer up to date.
+	// The lock is not held, as observers may call pipeline methods.
+	if p.observer != nil {
+		p.observer.OnCancel(err)
+	}
+	if p.logger != nil {
+		if err != nil {
+			p.logger.Warn("pipeline canceled", "error", err)
+		} else {
+			p.logger.Debug("pipeline done")
+		}
+	}
+
+	p.lock.Lock()
+	close(p.done)
+	p.cancelContext(err)
+	p.lock.Unlock()
+}
+
+// Drain gracefully stops the pipeline.
+// Source operators stop pulling values immediately, but the rest of operators keep processing the values already in flight,
+// including those in buffers and those sources already pulled, e.g. read from a Go channel, until the pipeline completes. Drain then returns the pipeline error, if any.
+// If ctx is done before the pipeline completes, the pipeline is canceled with the context error, which is then returned.
+//
+// Only source operators created before the pipeline started are stopped.
+// Sources created afterwards, like the inner channels of FlatMap, are considered values in flight, so they run to completion.
+// If the pipeline was never started, it is canceled and Drain returns immediately.
+func (p *Pipeline) Drain(ctx context.Context) error {
+	p.lock.Lock()
+	if !p.started { // no value is in flight, and Wait would block forever
+		p.lock.Unlock()
+		p.Cancel(nil)
+		return p.Error()
+	}
+	for _, node := range p.nodes {
+		if node.IsSource() {
+			node.Drain()
 		}
-		close(p.done)
 	}
+	p.lock.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		p.Cancel(contextError(ctx))
+		return p.Error()
+	}
+}
+
+// Pause makes source operators stop producing values until Resume is called.
+// Operators are kept alive, and values already in flight keep being processed, but Interval holds values until the pipeline is resumed.
+// If the pipeline is already paused, Pause has no effect.
+func (p *Pipeline) Pause() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.isPaused() {
+		return
+	}
+	close(p.paused)
+	p.resumed = make(chan struct{})
+}
+
+// Resume makes source operators produce values again after a Pause.
+// If the pipeline is not paused, Resume has no effect.
+func (p *Pipeline) Resume() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.isPaused() {
+		return
+	}
+	p.paused = make(chan struct{})
+	close(p.resumed)
+	p.resumedAt = time.Now()
+}
+
+// Started returns whether the pipeline has been started
+func (p *Pipeline) Started() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.started
+}
+
+// Paused returns whether the pipeline is paused
+func (p *Pipeline) Paused() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.isPaused()
+}
+
+func (p *Pipeline) isPaused() bool {
+	select {
+	case <-p.paused:
+		return true
+	default:
+		return false
+	}
+}
+
+// pauseSignals returns a channel that's closed while the pipeline is paused, and another one that's closed while it is not
+func (p *Pipeline) pauseSignals() (paused <-chan struct{}, resumed <-chan struct{}) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.paused, p.resumed
 }
 
 // Error returns the error in the pipeline if any.
 // It returns nil if the pipeline is still running, or it completed successfully.
+// If the pipeline was canceled by its context, the error wraps context.Canceled or context.DeadlineExceeded.
 func (p *Pipeline) Error() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
 	return p.err
 }
 
+// Wait blocks until the pipeline is done and all its operators have exited, and then returns the pipeline error, if any.
+// A pipeline with StartManually set to true must be started for Wait to return, unless it is canceled.
+func (p *Pipeline) Wait() error {
+	<-p.Done()
+
+	p.lock.Lock()
+	started := p.started
+	p.lock.Unlock()
+	if started {
+		<-p.nodesDone
+	}
+
+	return p.Error()
+}
+
+// Run creates a pipeline with the given context, calls build to construct it, starts it, and waits for it to be done.
+// The pipeline is only started after build returns, so build can safely create multiple sinks.
+// It returns the pipeline error, if any.
+//
+// Example:
+//
+//  err := jpipe.Run(ctx, func(p *jpipe.Pipeline) {
+//    jpipe.FromSlice(p, ids).ForEachErr(process, jpipe.Concurrent(5))
+//  })
+func Run(ctx context.Context, build func(p *Pipeline)) error {
+	pipeline := NewPipeline(Config{Context: ctx, StartManually: true})
+	build(pipeline)
+	pipeline.Start()
+	return pipeline.Wait()
+}
+
 // Done returns a channel that's close when the pipeline either completed successfully or failed.
 func (p *Pipeline) Done() <-chan struct{} {
 	return p.done
@@ -127,19 +369,77 @@ func (p *Pipeline) IsDone() bool {
 
 func (p *Pipeline) addNode(node pipelineNode) {
 	p.lock.Lock()
-	defer p.lock.Unlock()
 	if p.started {
 		node.Start() // nodes created for FlatMap after pipeline is started must be started immediately
+		p.dynamicNodes = append(p.dynamicNodes, node)
+		go func() {
+			<-node.Done()
+			p.removeDynamicNode(node)
+		}()
+		p.lock.Unlock()
 		return
 	}
 	p.nodes = append(p.nodes, node)
-	go func() { // done in a goroutine to avoid deadlock
-		if node.IsSink() && !p.startManually {
-			p.Start()
+	p.lock.Unlock()
+
+	if !node.IsSink() || p.startManually {
+		return
+	}
+	if p.validate {
+		if err := p.Validate(); err != nil { // validated right away, so the error doesn't depend on sinks created afterwards
+			if p.markStarted() {
+				p.abort(err)
+			}
+			return
 		}
-	}()
+	}
+	go p.Start() // done in a goroutine to avoid deadlock
+}
+
+// nodesSnapshot returns copies of the nodes created before the pipeline started, and those created afterwards that are still running
+func (p *Pipeline) nodesSnapshot() (staticNodes []pipelineNode, dynamicNodes []pipelineNode) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	staticNodes = make([]pipelineNode, len(p.nodes), len(p.nodes)+len(p.dynamicNodes))
+	copy(staticNodes, p.nodes)
+	return staticNodes, append([]pipelineNode{}, p.dynamicNodes...)
 }
 
+func (p *Pipeline) removeDynamicNode(node pipelineNode) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for i := range p.dynamicNodes {
+		if p.dynamicNodes[i] == node {
+			p.dynamicNodes = append(p.dynamicNodes[:i], p.dynamicNodes[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *Pipeline) nextNodeID() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	id := p.nodeCount
+	p.nodeCount++
+	return id
+}
+
+// Name returns the name set in the pipeline's Config
+func (p *Pipeline) Name() string {
+	return p.name
+}
+
+// Context returns a context that's canceled when the pipeline is done, either because it completed successfully or failed.
+// It is derived from the context in the pipeline's Config, and context.Cause returns the pipeline error if there was one.
 func (p *Pipeline) Context() context.Context {
 	return p.context
 }
+
+// contextError returns the error of a done context, making sure it wraps both the context error and its cause
+func contextError(ctx context.Context) error {
+	err, cause := ctx.Err(), context.Cause(ctx)
+	if cause == nil || cause == err {
+		return err
+	}
+	return fmt.Errorf("%w: %w", err, cause)
+}
@@ -2,11 +2,14 @@ package jpipe_test
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/junitechnology/jpipe"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
 )
 
 func TestPipelineDoneWhenContextDone(t *testing.T) {
@@ -34,6 +37,311 @@ func TestPipelineDoneWhenContextDone(t *testing.T) {
 	assert.ErrorIs(t, pipeline.Error(), context.Canceled)
 }
 
+func TestPipelineContext(t *testing.T) {
+	t.Run("Context is canceled with the error when the pipeline is canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.Background())
+		jpipe.
+			FromSlice(pipeline, []int{1, 2, 3}).
+			Interval(func(value int) time.Duration { return 100 * time.Millisecond }).
+			ToSlice()
+		errTest := errors.New("test error")
+
+		assert.NoError(t, pipeline.Context().Err())
+		pipeline.Cancel(errTest)
+
+		assert.ErrorIs(t, pipeline.Context().Err(), context.Canceled)
+		assert.ErrorIs(t, context.Cause(pipeline.Context()), errTest)
+	})
+
+	t.Run("Context is canceled when the pipeline completes", func(t *testing.T) {
+		pipeline := jpipe.New(context.Background())
+		<-jpipe.FromSlice(pipeline, []int{1, 2, 3}).ToSlice()
+
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Context().Err(), context.Canceled)
+	})
+}
+
+func TestPipelineWait(t *testing.T) {
+	t.Run("Returns nil when the pipeline completes successfully", func(t *testing.T) {
+		pipeline := jpipe.New(context.Background())
+		values := []int{}
+		jpipe.FromSlice(pipeline, []int{1, 2, 3}).ForEach(func(value int) { values = append(values, value) })
+
+		err := pipeline.Wait()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assert.True(t, pipeline.IsDone())
+	})
+
+	t.Run("Returns the pipeline error", func(t *testing.T) {
+		pipeline := jpipe.New(context.Background())
+		errTest := errors.New("test error")
+		jpipe.FromSlice(pipeline, []int{1, 2, 3}).ForEachErr(func(value int) error { return errTest })
+
+		err := pipeline.Wait()
+
+		assert.ErrorIs(t, err, errTest)
+	})
+
+	t.Run("Returns an error wrapping the context error and its cause", func(t *testing.T) {
+		errCause := errors.New("cause")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		pipeline := jpipe.New(ctx)
+		jpipe.FromGoChannel(pipeline, make(chan int)).ToSlice()
+
+		cancel(errCause)
+		err := pipeline.Wait()
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, err, errCause)
+	})
+
+	t.Run("Returns an error wrapping context.DeadlineExceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		pipeline := jpipe.New(ctx)
+		jpipe.FromGoChannel(pipeline, make(chan int)).ToSlice()
+
+		err := pipeline.Wait()
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+// nodeDoneObserver closes done when the only operator with the given name exits
+type nodeDoneObserver struct {
+	jpipe.BaseObserver
+	nodeName string
+	done     chan struct{}
+}
+
+func (o *nodeDoneObserver) OnNodeDone(node jpipe.NodeInfo) {
+	if node.Name == o.nodeName {
+		close(o.done)
+	}
+}
+
+func TestPipelineDrain(t *testing.T) {
+	t.Run("Stops sources and processes values in flight", func(t *testing.T) {
+		observer := &recordingObserver{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Observer: observer})
+		goChannel := make(chan int)
+		values := []int{}
+		done := jpipe.FromGoChannel(pipeline, goChannel).
+			Buffer(10).
+			ForEach(func(value int) {
+				time.Sleep(10 * time.Millisecond)
+				values = append(values, value)
+			})
+		for i := 1; i <= 5; i++ {
+			goChannel <- i
+		}
+		assert.Eventually(t, func() bool { // the last value is in flight once sent to the buffer
+			return slices.Contains(observer.getEvents(), "sent FromGoChannel 5")
+		}, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := pipeline.Drain(ctx)
+
+		assert.NoError(t, err)
+		assertChannelClosed(t, done, 10*time.Millisecond)
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, values)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Keeps processing FlatMap inner channels", func(t *testing.T) {
+		pipeline := jpipe.New(context.Background())
+		goChannel := make(chan int)
+		mapped := make(chan struct{})
+		flatMapped := jpipe.FlatMap(jpipe.FromGoChannel(pipeline, goChannel), func(value int) *jpipe.Channel[int] {
+			close(mapped)
+			return jpipe.FromSlice(pipeline, []int{value, value * 10})
+		})
+		result := flatMapped.Buffer(10).ToSlice()
+		goChannel <- 1
+		<-mapped
+
+		err := pipeline.Drain(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 10}, <-result)
+	})
+
+	t.Run("Sends the value a source is blocked sending", func(t *testing.T) {
+		observer := &nodeDoneObserver{nodeName: "idle", done: make(chan struct{})}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Observer: observer, StartManually: true})
+		goChannel := make(chan int)
+		values := []int{}
+		done := jpipe.FromGoChannel(pipeline, goChannel).
+			ForEach(func(value int) {
+				// the source is blocked sending the next value until the pipeline is drained.
+				// Sources are drained in creation order, so the idle one exiting means the other one was drained too.
+				<-observer.done
+				values = append(values, value)
+			})
+		jpipe.FromGoChannel(pipeline, make(chan int)).Discard(jpipe.Name("idle"))
+		pipeline.Start()
+		goChannel <- 1
+		goChannel <- 2
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := pipeline.Drain(ctx)
+
+		assert.NoError(t, err)
+		assertChannelClosed(t, done, 10*time.Millisecond)
+		assert.Equal(t, []int{1, 2}, values)
+	})
+
+	t.Run("Returns immediately if the pipeline never started", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true})
+		jpipe.FromSlice(pipeline, []int{1, 2, 3}).ToSlice()
+
+		err := pipeline.Drain(context.Background())
+
+		assert.NoError(t, err)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Cancels the pipeline if the context is done first", func(t *testing.T) {
+		pipeline := jpipe.New(context.Background())
+		started := make(chan struct{})
+		done := jpipe.FromSlice(pipeline, []int{1, 2, 3}).
+			ForEachCtx(func(ctx context.Context, value int) error {
+				close(started)
+				<-ctx.Done()
+				return nil
+			})
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := pipeline.Drain(ctx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, pipeline.Error(), context.DeadlineExceeded)
+		assertChannelClosed(t, done, 10*time.Millisecond)
+	})
+}
+
+func TestPipelinePause(t *testing.T) {
+	t.Run("Sources stop producing while paused", func(t *testing.T) {
+		pipeline := jpipe.New(context.Background())
+		goChannel := jpipe.FromGenerator(pipeline, func(i uint64) uint64 { return i }).ToGoChannel()
+		readGoChannel(goChannel, 3)
+
+		pipeline.Pause()
+		assert.True(t, pipeline.Paused())
+		for i := 0; i < 2; i++ { // values already in flight may still arrive
+			select {
+			case <-goChannel:
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		assertChannelOpenButNoValue(t, goChannel, 20*time.Millisecond)
+
+		pipeline.Resume()
+		assert.False(t, pipeline.Paused())
+		select {
+		case <-goChannel:
+		case <-time.After(10 * time.Millisecond):
+			assert.Fail(t, "Sources should produce values after the pipeline is resumed")
+		}
+		assert.False(t, pipeline.IsDone())
+		cancelPipeline(pipeline)
+	})
+
+	t.Run("Paused pipeline can be canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.Background())
+		goChannel := jpipe.FromGoChannel(pipeline, make(chan int)).ToGoChannel()
+
+		pipeline.Pause()
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Builds, starts and waits for the pipeline", func(t *testing.T) {
+		var values1, values2 []int
+
+		err := jpipe.Run(context.Background(), func(p *jpipe.Pipeline) {
+			channels := jpipe.FromSlice(p, []int{1, 2, 3}).Broadcast(2)
+			channels[0].ForEach(func(value int) { values1 = append(values1, value) })
+			channels[1].ForEach(func(value int) { values2 = append(values2, value) })
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, values1)
+		assert.Equal(t, []int{1, 2, 3}, values2)
+	})
+
+	t.Run("Returns the pipeline error", func(t *testing.T) {
+		err := jpipe.Run(context.Background(), func(p *jpipe.Pipeline) {
+			jpipe.MapErr(jpipe.FromSlice(p, []string{"1", "A"}), strconv.Atoi).ToSlice()
+		})
+
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+	})
+}
+
+func TestPipelineValidateOnStart(t *testing.T) {
+	t.Run("Runs normally if all channels are consumed", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true, Validate: true})
+		channels := jpipe.FromSlice(pipeline, []int{1, 2, 3}).Broadcast(2)
+		channels[0].Discard()
+		values := channels[1].ToSlice()
+
+		pipeline.Start()
+
+		assert.Equal(t, []int{1, 2, 3}, <-values)
+		assert.NoError(t, pipeline.Wait())
+	})
+
+	t.Run("Cancels pipeline without starting operators if some channel is not consumed", func(t *testing.T) {
+		observer := &recordingObserver{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{StartManually: true, Validate: true, Observer: observer})
+		channels := jpipe.FromSlice(pipeline, []int{1, 2, 3}).Broadcast(2)
+		values := channels[1].ToSlice()
+
+		pipeline.Start()
+
+		err := pipeline.StartErr()
+		assert.ErrorIs(t, err, jpipe.ErrUnconsumedChannel)
+		assert.Equal(t, "unconsumed channel: output 0 of Broadcast (#1)", err.Error())
+		assert.True(t, pipeline.IsDone())
+		assert.Empty(t, <-values)
+		assert.Equal(t, err, pipeline.Wait())
+		assert.Equal(t, []string{"cancel " + err.Error()}, observer.getEvents()) // no operator ever started
+	})
+
+	t.Run("Cancels pipeline when the sink starting it automatically is created", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{Validate: true})
+		channels := jpipe.FromSlice(pipeline, []int{1, 2, 3}).Broadcast(2)
+
+		done := channels[1].ForEach(func(int) {})
+
+		assert.True(t, pipeline.IsDone())
+		assertChannelClosed(t, done, 10*time.Millisecond)
+		assert.EqualError(t, pipeline.StartErr(), "unconsumed channel: output 0 of Broadcast (#1)")
+		assert.ErrorIs(t, pipeline.StartErr(), jpipe.ErrUnconsumedChannel)
+		assert.ErrorIs(t, pipeline.Wait(), jpipe.ErrUnconsumedChannel)
+	})
+
+	t.Run("Has no start error if all channels are consumed", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{Validate: true})
+		<-jpipe.FromSlice(pipeline, []int{1, 2, 3}).ToSlice()
+
+		assert.NoError(t, pipeline.Wait())
+		assert.NoError(t, pipeline.StartErr())
+	})
+}
+
 func TestPipelineRecoversFromPanicAndIncludesStacktrace(t *testing.T) {
 	pipeline := jpipe.NewPipeline(jpipe.Config{})
 	jpipe.
@@ -52,3 +360,78 @@ func TestPipelineRecoversFromPanicAndIncludesStacktrace(t *testing.T) {
 	assert.Contains(t, pipeline.Error().Error(), "panic")
 	assert.Contains(t, pipeline.Error().Error(), "TestPipelineRecoversFromPanicAndIncludesStacktrace") // this shows that the stacktrace is included
 }
+
+func TestPipelineReturnsPanicError(t *testing.T) {
+	t.Run("Panic error has the recovered value, stack and node", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{})
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		jpipe.Map(channel, func(value int) int { panic("panic") }).ToSlice()
+
+		err := pipeline.Wait()
+
+		var panicErr *jpipe.PanicError
+		assert.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "panic", panicErr.Value)
+		assert.Equal(t, "Map", panicErr.NodeType)
+		assert.Equal(t, 1, panicErr.NodeID)
+		assert.Contains(t, string(panicErr.Stack), "TestPipelineReturnsPanicError")
+	})
+
+	t.Run("Panic error unwraps recovered errors", func(t *testing.T) {
+		errTest := errors.New("test error")
+		pipeline := jpipe.NewPipeline(jpipe.Config{})
+		jpipe.FromSlice(pipeline, []int{1, 2, 3}).ForEach(func(value int) { panic(errTest) })
+
+		err := pipeline.Wait()
+
+		var panicErr *jpipe.PanicError
+		assert.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "ForEach", panicErr.NodeType)
+		assert.ErrorIs(t, err, errTest)
+	})
+
+	t.Run("Panic error keeps the original stack with timeouts", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{})
+		jpipe.FromSlice(pipeline, []int{1, 2, 3}).ForEach(func(value int) { panic("panic") }, jpipe.Timeout(time.Second))
+
+		err := pipeline.Wait()
+
+		var panicErr *jpipe.PanicError
+		assert.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "ForEach", panicErr.NodeType)
+		assert.Contains(t, string(panicErr.Stack), "TestPipelineReturnsPanicError")
+	})
+}
+
+func TestPipelineReturnsStageError(t *testing.T) {
+	t.Run("Stage error identifies the failing operator", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{})
+		channel := jpipe.FromSlice(pipeline, []string{"1", "2", "A"})
+		channel = jpipe.Map(channel, func(s string) string { return s })
+		jpipe.MapErr(channel, strconv.Atoi, jpipe.Name("parse-ints")).ToSlice()
+
+		err := pipeline.Wait()
+
+		var stageErr *jpipe.StageError
+		assert.ErrorAs(t, err, &stageErr)
+		assert.Equal(t, "MapErr", stageErr.NodeType)
+		assert.Equal(t, "parse-ints", stageErr.NodeName)
+		assert.Equal(t, 2, stageErr.Index)
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+		assert.Contains(t, err.Error(), `MapErr "parse-ints" (#2)`)
+	})
+
+	t.Run("Stage error wraps panic errors", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{})
+		jpipe.FromSlice(pipeline, []int{1, 2, 3}).ForEach(func(value int) { panic("panic") }, jpipe.Name("printer"))
+
+		err := pipeline.Wait()
+
+		var stageErr *jpipe.StageError
+		var panicErr *jpipe.PanicError
+		assert.ErrorAs(t, err, &stageErr)
+		assert.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "printer", stageErr.NodeName)
+		assert.Equal(t, "ForEach", panicErr.NodeType)
+	})
+}
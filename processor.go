@@ -1,12 +1,16 @@
 package jpipe
 
 import (
+	"context"
+	"math/rand"
+	"runtime/debug"
 	"sync"
+	"time"
 
 	"github.com/junitechnology/jpipe/options"
 )
 
-type processor[T any, R any] func(value T) (outputValue R, send bool)
+type processor[T any, R any] func(ctx context.Context, value T) (outputValue R, send bool, err error)
 
 func (processor processor[T, R]) PooledWorker(opts ...options.PooledWorkerOption) worker[T, R] {
 	concurrent := getOptionOrDefault(opts, Concurrent(1))
@@ -21,10 +25,173 @@ func (processor processor[T, R]) PooledWorker(opts ...options.PooledWorkerOption
 	return processor.orderedPooledWorker(concurrent.Concurrency, ordered.OrderBufferSize)
 }
 
+// An operatorFunc is the most general form of a function passed to an operator.
+// Operator functions are adapted to it, so behaviors like retries can be applied uniformly.
+type operatorFunc[T any, R any] func(ctx context.Context, value T) (R, error)
+
+// MapWorker returns a worker that sends every output of the function
+func (function operatorFunc[T, R]) MapWorker(opts ...options.PooledWorkerOption) worker[T, R] {
+	function = function.decorated(opts)
+	var processor processor[T, R] = func(ctx context.Context, value T) (R, bool, error) {
+		output, err := function(ctx, value)
+		return output, err == nil, err
+	}
+
+	return processor.PooledWorker(opts...)
+}
+
+// SinkWorker returns a worker that calls the function for every input value, and sends nothing
+func (function operatorFunc[T, R]) SinkWorker(opts ...options.PooledWorkerOption) worker[T, any] {
+	function = function.decorated(opts)
+	var processor processor[T, any] = func(ctx context.Context, value T) (any, bool, error) {
+		_, err := function(ctx, value)
+		return nil, false, err
+	}
+
+	return processor.PooledWorker(opts...)
+}
+
+// filterWorker returns a worker that sends only the input values matching the predicate
+func filterWorker[T any](predicate operatorFunc[T, bool], opts ...options.PooledWorkerOption) worker[T, T] {
+	predicate = predicate.decorated(opts)
+	var processor processor[T, T] = func(ctx context.Context, value T) (T, bool, error) {
+		match, err := predicate(ctx, value)
+		return value, match && err == nil, err
+	}
+
+	return processor.PooledWorker(opts...)
+}
+
+func (function operatorFunc[T, R]) decorated(opts []options.PooledWorkerOption) operatorFunc[T, R] {
+	if timeout := getOption[options.PooledWorkerOption, options.Timeout](opts); timeout != nil {
+		function = function.withTimeout(timeout.Duration)
+	}
+	if retry := getOption[options.PooledWorkerOption, options.Retry](opts); retry != nil {
+		function = function.withRetry(*retry)
+	}
+
+	return function
+}
+
+// withTimeout runs every call with a context that expires after the timeout.
+// The call runs in its own goroutine, so it doesn't block the worker forever if the function ignores its context.
+func (function operatorFunc[T, R]) withTimeout(timeout time.Duration) operatorFunc[T, R] {
+	type result struct {
+		output R
+		err    error
+		panic  *PanicError
+	}
+
+	return func(ctx context.Context, value T) (R, error) {
+		timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resultCh := make(chan result, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					resultCh <- result{panic: &PanicError{Value: r, Stack: debug.Stack()}}
+				}
+			}()
+			output, err := function(timeoutCtx, value)
+			resultCh <- result{output: output, err: err}
+		}()
+
+		select {
+		case r := <-resultCh:
+			if r.panic != nil {
+				panic(r.panic) // propagate the panic to the worker, so it's handled as usual
+			}
+			return r.output, r.err
+		case <-timeoutCtx.Done():
+			var zero R
+			if ctx.Err() != nil {
+				return zero, ctx.Err() // the node is quitting, not a timeout
+			}
+			return zero, ErrTimeout
+		}
+	}
+}
+
+// withRetry retries failed calls. A panic is retried as a *PanicError error, and it is propagated if the last attempt panics too,
+// so retries also work for functions that can't return an error, e.g. in Map.
+func (function operatorFunc[T, R]) withRetry(retry options.Retry) operatorFunc[T, R] {
+	call := func(ctx context.Context, value T) (output R, err error, panicked bool) {
+		defer func() {
+			if r := recover(); r != nil {
+				panicErr, ok := r.(*PanicError)
+				if !ok { // panics forwarded by withTimeout are already a *PanicError with the original stack
+					panicErr = &PanicError{Value: r, Stack: debug.Stack()}
+				}
+				err, panicked = panicErr, true
+			}
+		}()
+		output, err = function(ctx, value)
+		return output, err, false
+	}
+
+	return func(ctx context.Context, value T) (R, error) {
+		for attempt := 1; ; attempt++ {
+			output, err, panicked := call(ctx, value)
+			if err == nil || attempt >= retry.MaxAttempts || (retry.Retryable != nil && !retry.Retryable(err)) {
+				if panicked {
+					panic(err) // propagate the panic to the worker, so it's handled as usual
+				}
+				return output, err
+			}
+
+			delay := retryDelay(retry, attempt)
+			if logger := loggerFromContext(ctx); logger != nil {
+				logger.Warn("retrying operator function", "attempt", attempt, "delay", delay, "error", err)
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done(): // the node is quitting, so we exit immediately
+				timer.Stop()
+				if panicked {
+					panic(err)
+				}
+				return output, err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// retryDelay calculates the exponential backoff before the next attempt, with a random jitter applied.
+func retryDelay(retry options.Retry, attempt int) time.Duration {
+	delay := float64(retry.InitialDelay)
+	for i := 1; i < attempt; i++ {
+		delay *= retry.Multiplier
+		if retry.MaxDelay > 0 && delay >= float64(retry.MaxDelay) {
+			delay = float64(retry.MaxDelay)
+			break
+		}
+	}
+	delay += delay * retry.Jitter * (2*rand.Float64() - 1)
+	if delay < 0 {
+		delay = 0
+	}
+
+	return time.Duration(delay)
+}
+
+// process calls the processor with the node context, recording its latency in the node stats
+func (processor processor[T, R]) process(node workerNode[T, R], value T) (R, bool, error) {
+	start := time.Now()
+	defer func() { node.RecordLatency(time.Since(start)) }()
+	return processor(node.Context(), value)
+}
+
 func (processor processor[T, R]) singleLoopWorker() worker[T, R] {
 	return func(node workerNode[T, R]) {
 		node.LoopInput(0, func(value T) bool {
-			if output, send := processor(value); send {
+			output, send, err := processor.process(node, value)
+			if err != nil {
+				node.Cancel(err)
+				return false
+			}
+			if send {
 				return node.Send(output)
 			}
 			return true
@@ -47,7 +214,11 @@ func (processor processor[T, R]) orderedPooledWorker(concurrency int, orderBuffe
 				}()
 
 				loopOverChannel(node, internalInput, func(value orderedValue[T]) bool {
-					output, send := processor(*value.value)
+					output, send, err := processor.process(node, *value.value)
+					if err != nil {
+						node.Cancel(err)
+						return false
+					}
 					return orderingBuffer.Send(orderedValue[R]{value: &output, idx: value.idx, skip: !send})
 				})
 			}()
@@ -56,7 +227,11 @@ func (processor processor[T, R]) orderedPooledWorker(concurrency int, orderBuffe
 
 		idx := int64(0)
 		node.LoopInput(0, func(value T) bool {
-			internalInput <- orderedValue[T]{value: &value, idx: idx}
+			select {
+			case <-node.QuitSignal(): // workers may have exited already, so we must not block on the internal input
+				return false
+			case internalInput <- orderedValue[T]{value: &value, idx: idx}:
+			}
 			idx++
 			return true
 		})
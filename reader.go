@@ -0,0 +1,274 @@
+package jpipe
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/junitechnology/jpipe/item"
+	"github.com/junitechnology/jpipe/options"
+)
+
+// FromReaderLines creates a Channel from the lines read from the reader, without the trailing end-of-line marker.
+// If reading fails, the pipeline is canceled with the error.
+// If the reader is an io.Closer, it is closed when the operator exits. It is closed as soon as the operator quits, so blocked reads are interrupted.
+func FromReaderLines(pipeline *Pipeline, reader io.Reader, opts ...options.ReaderOption) *Channel[string] {
+	lines := newLineReader(reader)
+	read := func() (readResult[string], error) {
+		line, err := lines.next()
+		return readResult[string]{value: line}, err
+	}
+
+	return fromReader("FromReaderLines", pipeline, reader, read, sendValue[string], getNodeOptions(opts))
+}
+
+// FromCSV creates a Channel from the records read from the reader in CSV format. Every record is a slice with its fields.
+// The format can be customized with the CSV option, e.g. to use another delimiter or skip a header.
+// If reading or parsing fails, the pipeline is canceled with the error.
+// If the reader is an io.Closer, it is closed when the operator exits. It is closed as soon as the operator quits, so blocked reads are interrupted.
+func FromCSV(pipeline *Pipeline, reader io.Reader, opts ...options.CSVOption) *Channel[[]string] {
+	return fromReader("FromCSV", pipeline, reader, csvFieldsReader(reader, opts), sendValue[[]string], getNodeOptions(opts))
+}
+
+// FromCSVItems works like FromCSV, but malformed records are sent as errored items instead of canceling the pipeline.
+// Errors reading from the reader still cancel the pipeline.
+func FromCSVItems(pipeline *Pipeline, reader io.Reader, opts ...options.CSVOption) *Channel[item.Item[[]string]] {
+	return fromReader("FromCSVItems", pipeline, reader, csvFieldsReader(reader, opts), sendItem[[]string], getNodeOptions(opts))
+}
+
+// FromCSVRecords creates a Channel from the records read from the reader in CSV format, mapping every record to a struct of type T.
+// Fields are matched to columns by position, so every record must have a column for every field, or by name if the CSV option sets a header.
+// The column name of a field is its name, or the name in its csv tag, e.g. `csv:"user_id"`. Fields with the `csv:"-"` tag are ignored.
+// Fields may be strings, booleans, numbers, durations or implement encoding.TextUnmarshaler. Empty columns leave the field with its zero value.
+// If reading, parsing or mapping fails, the pipeline is canceled with the error.
+// If the reader is an io.Closer, it is closed when the operator exits. It is closed as soon as the operator quits, so blocked reads are interrupted.
+//
+// Example:
+//
+//  type User struct {
+//    ID   int    `csv:"id"`
+//    Name string `csv:"name"`
+//  }
+//
+//  users := FromCSVRecords[User](pipeline, file, CSV().WithHeader())
+func FromCSVRecords[T any](pipeline *Pipeline, reader io.Reader, opts ...options.CSVOption) *Channel[T] {
+	return fromReader("FromCSVRecords", pipeline, reader, csvRecordsReader[T](reader, opts), sendValue[T], getNodeOptions(opts))
+}
+
+// FromCSVRecordsItems works like FromCSVRecords, but malformed records or records that can't be mapped to T
+// are sent as errored items instead of canceling the pipeline. Errors reading from the reader still cancel the pipeline.
+func FromCSVRecordsItems[T any](pipeline *Pipeline, reader io.Reader, opts ...options.CSVOption) *Channel[item.Item[T]] {
+	return fromReader("FromCSVRecordsItems", pipeline, reader, csvRecordsReader[T](reader, opts), sendItem[T], getNodeOptions(opts))
+}
+
+// FromJSONLines creates a Channel from the lines read from the reader in JSON Lines format, decoding every line as a value of type T.
+// Blank lines are ignored. If reading or decoding fails, the pipeline is canceled with the error.
+// If the reader is an io.Closer, it is closed when the operator exits. It is closed as soon as the operator quits, so blocked reads are interrupted.
+func FromJSONLines[T any](pipeline *Pipeline, reader io.Reader, opts ...options.ReaderOption) *Channel[T] {
+	return fromReader("FromJSONLines", pipeline, reader, jsonLinesReader[T](reader), sendValue[T], getNodeOptions(opts))
+}
+
+// FromJSONLinesItems works like FromJSONLines, but lines that can't be decoded are sent as errored items instead of canceling the pipeline.
+// Errors reading from the reader still cancel the pipeline.
+func FromJSONLinesItems[T any](pipeline *Pipeline, reader io.Reader, opts ...options.ReaderOption) *Channel[item.Item[T]] {
+	return fromReader("FromJSONLinesItems", pipeline, reader, jsonLinesReader[T](reader), sendItem[T], getNodeOptions(opts))
+}
+
+// A readResult is a value read by a readFunc, or the error decoding it, which only affects that value
+type readResult[T any] struct {
+	value     T
+	decodeErr error
+}
+
+// A readFunc returns the next value read. Any error other than a decoding error is returned as err, which is io.EOF when there are no more values.
+type readFunc[T any] func() (result readResult[T], err error)
+
+// fromReader creates a source sending the values read with read, converted by toOutput.
+// toOutput returns an error if the value can't be sent, which cancels the pipeline.
+func fromReader[T any, R any](nodeType string, pipeline *Pipeline, reader io.Reader, read readFunc[T], toOutput func(readResult[T]) (R, error), opts []options.NodeOption) *Channel[R] {
+	worker := func(node workerNode[any, R]) {
+		defer closeOnQuit(node, reader)()
+		for produce(node) {
+			result, err := read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				node.Cancel(err)
+				return
+			}
+			output, err := toOutput(result)
+			if err != nil {
+				node.Cancel(err)
+				return
+			}
+			if !node.Send(output) {
+				return
+			}
+		}
+	}
+
+	_, output := newSourcePipelineNode(nodeType, pipeline, worker, opts...)
+	return output
+}
+
+func sendValue[T any](result readResult[T]) (T, error) {
+	return result.value, result.decodeErr
+}
+
+func sendItem[T any](result readResult[T]) (item.Item[T], error) {
+	if result.decodeErr != nil {
+		return ErrorItem[T](result.decodeErr), nil
+	}
+	return ValueItem(result.value), nil
+}
+
+// closeOnQuit closes the reader, if it is an io.Closer, as soon as the node quits, or when the returned function is called.
+// The returned function waits for the reader to be closed.
+func closeOnQuit[R any](node workerNode[any, R], reader io.Reader) func() {
+	closer, ok := reader.(io.Closer)
+	if !ok {
+		return func() {}
+	}
+
+	exited := make(chan struct{})
+	closed := make(chan struct{})
+	go func() {
+		select {
+		case <-node.QuitSignal(): // closing interrupts a blocked read
+		case <-exited:
+		}
+		closer.Close()
+		close(closed)
+	}()
+
+	return func() {
+		close(exited)
+		<-closed
+	}
+}
+
+type lineReader struct {
+	reader *bufio.Reader
+	line   int
+}
+
+func newLineReader(reader io.Reader) *lineReader {
+	return &lineReader{reader: bufio.NewReader(reader)}
+}
+
+// next returns the next line without the trailing end-of-line marker, or io.EOF if there are no more lines.
+// Unlike bufio.Scanner, lines can have any length.
+func (r *lineReader) next() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil // the last line has no end-of-line marker
+	}
+	if err != nil {
+		return "", err
+	}
+	r.line++
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+func jsonLinesReader[T any](reader io.Reader) readFunc[T] {
+	lines := newLineReader(reader)
+	return func() (readResult[T], error) {
+		var result readResult[T]
+		for {
+			line, err := lines.next()
+			if err != nil {
+				return result, err
+			}
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(line), &result.value); err != nil {
+				result.decodeErr = fmt.Errorf("line %d: %w", lines.line, err)
+			}
+			return result, nil
+		}
+	}
+}
+
+func newCSVReader(reader io.Reader, csvOpt options.CSV) *csv.Reader {
+	csvReader := csv.NewReader(reader)
+	if csvOpt.Comma != 0 {
+		csvReader.Comma = csvOpt.Comma
+	}
+	csvReader.Comment = csvOpt.Comment
+	csvReader.FieldsPerRecord = -1 // records may have a variable number of fields, and FromCSVRecords checks them when mapping
+	return csvReader
+}
+
+// readCSV reads a record, telling apart parse errors, which only affect the record, from reader errors
+func readCSV(csvReader *csv.Reader) (readResult[[]string], error) {
+	record, err := csvReader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return readResult[[]string]{decodeErr: err}, nil
+	}
+	return readResult[[]string]{value: record}, err
+}
+
+// readCSVHeader reads the header record. A malformed header is not a decode error, since no record can be read without it
+func readCSVHeader(csvReader *csv.Reader) ([]string, error) {
+	result, err := readCSV(csvReader)
+	if err != nil {
+		return nil, err
+	}
+	return result.value, result.decodeErr
+}
+
+func csvFieldsReader(reader io.Reader, opts []options.CSVOption) readFunc[[]string] {
+	csvOpt := getOptionOrDefault(opts, CSV())
+	csvReader := newCSVReader(reader, csvOpt)
+	skipHeader := csvOpt.Header
+	return func() (readResult[[]string], error) {
+		if skipHeader {
+			skipHeader = false
+			if _, err := readCSVHeader(csvReader); err != nil {
+				return readResult[[]string]{}, err
+			}
+		}
+		return readCSV(csvReader)
+	}
+}
+
+func csvRecordsReader[T any](reader io.Reader, opts []options.CSVOption) readFunc[T] {
+	csvOpt := getOptionOrDefault(opts, CSV())
+	csvReader := newCSVReader(reader, csvOpt)
+	var mapper *csvMapper[T]
+	return func() (readResult[T], error) {
+		var result readResult[T]
+		if mapper == nil {
+			var header []string
+			if csvOpt.Header {
+				var err error
+				if header, err = readCSVHeader(csvReader); err != nil {
+					return result, err
+				}
+			}
+			var err error
+			if mapper, err = newCSVMapper[T](header, canParseCSVField); err != nil {
+				return result, err
+			}
+		}
+
+		record, err := readCSV(csvReader)
+		if err != nil || record.decodeErr != nil {
+			result.decodeErr = record.decodeErr
+			return result, err
+		}
+		line, _ := csvReader.FieldPos(0)
+		if result.value, err = mapper.mapRecord(record.value); err != nil {
+			result.decodeErr = fmt.Errorf("record on line %d: %w", line, err)
+		}
+		return result, nil
+	}
+}
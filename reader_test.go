@@ -0,0 +1,253 @@
+package jpipe_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/junitechnology/jpipe"
+	"github.com/junitechnology/jpipe/item"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+func TestFromReaderLines(t *testing.T) {
+	t.Run("Creates channel from lines", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromReaderLines(pipeline, strings.NewReader("line 1\r\nline 2\n\nline 4"))
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []string{"line 1", "line 2", "", "line 4"}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on read error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		errTest := errors.New("test error")
+		channel := jpipe.FromReaderLines(pipeline, failingReader{err: errTest})
+
+		values := drainChannel(channel)
+
+		assert.Empty(t, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+
+	t.Run("Closes reader when done", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		reader, writer := io.Pipe()
+		go func() {
+			writer.Write([]byte("line 1\n"))
+			writer.Close()
+		}()
+		channel := jpipe.FromReaderLines(pipeline, reader)
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []string{"line 1"}, values)
+		_, err := reader.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+
+	t.Run("Closes reader on pipeline canceled, interrupting a blocked read", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		reader, writer := io.Pipe()
+		channel := jpipe.FromReaderLines(pipeline, reader)
+		goChannel := channel.ToGoChannel()
+
+		go writer.Write([]byte("line 1\n"))
+		readGoChannel(goChannel, 1)
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		_, err := writer.Write([]byte("line 2\n"))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+		assert.NoError(t, pipeline.Error())
+	})
+}
+
+func TestFromCSV(t *testing.T) {
+	t.Run("Creates channel from records", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromCSV(pipeline, strings.NewReader("a,b\n\"c,d\",e\n"))
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, [][]string{{"a", "b"}, {"c,d", "e"}}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Applies CSV options", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromCSV(pipeline, strings.NewReader("id;name\n# comment\n1;a\n2;b\n"),
+			jpipe.CSV().WithComma(';').WithComment('#').WithHeader())
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, [][]string{{"1", "a"}, {"2", "b"}}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Cancels pipeline on parse error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromCSV(pipeline, strings.NewReader("a,b\nc,\"d\n"))
+
+		drainChannel(channel)
+
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		var parseErr *csv.ParseError
+		assert.ErrorAs(t, pipeline.Error(), &parseErr)
+	})
+}
+
+func TestFromCSVItems(t *testing.T) {
+	t.Run("Sends parse errors as errored items", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromCSVItems(pipeline, strings.NewReader("a,b\nc,d\"\ne,f\n"))
+
+		values := drainChannel(channel)
+
+		assert.Len(t, values, 3)
+		assert.Equal(t, jpipe.ValueItem([]string{"a", "b"}), values[0])
+		var parseErr *csv.ParseError
+		assert.ErrorAs(t, values[1].Error, &parseErr)
+		assert.Equal(t, jpipe.ValueItem([]string{"e", "f"}), values[2])
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
+
+type csvUser struct {
+	ID      int           `csv:"id"`
+	Name    string        `csv:"name"`
+	Active  bool          `csv:"active"`
+	Timeout time.Duration `csv:"timeout"`
+	Ignored string        `csv:"-"`
+}
+
+func TestFromCSVRecords(t *testing.T) {
+	t.Run("Maps records to structs by position", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromCSVRecords[csvUser](pipeline, strings.NewReader("1,a,true,1s\n2,b,,\n"))
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []csvUser{{ID: 1, Name: "a", Active: true, Timeout: time.Second}, {ID: 2, Name: "b"}}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Maps records to structs by header", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromCSVRecords[csvUser](pipeline, strings.NewReader("name,unknown,id\na,x,1\nb,y,2\n"), jpipe.CSV().WithHeader())
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []csvUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on mapping error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromCSVRecords[csvUser](pipeline, strings.NewReader("1,a,true,1s\nX,b,false,1s\n"))
+
+		drainChannel(channel)
+
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), strconv.ErrSyntax)
+		assert.ErrorContains(t, pipeline.Error(), `record on line 2: column "id"`)
+	})
+
+	t.Run("Cancels pipeline if type is not a struct", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromCSVRecords[int](pipeline, strings.NewReader("1\n"))
+
+		values := drainChannel(channel)
+
+		assert.Empty(t, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.Error(t, pipeline.Error())
+	})
+}
+
+func TestFromCSVRecordsItems(t *testing.T) {
+	t.Run("Sends mapping errors as errored items", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromCSVRecordsItems[csvUser](pipeline, strings.NewReader("1,a,,\nX,b,,\n3\n4,d,,\n"))
+
+		values := drainChannel(channel)
+
+		assert.Len(t, values, 4)
+		assert.Equal(t, jpipe.ValueItem(csvUser{ID: 1, Name: "a"}), values[0])
+		assert.ErrorIs(t, values[1].Error, strconv.ErrSyntax)
+		assert.Error(t, values[2].Error)
+		assert.Equal(t, jpipe.ValueItem(csvUser{ID: 4, Name: "d"}), values[3])
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
+
+type jsonUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestFromJSONLines(t *testing.T) {
+	t.Run("Decodes every line", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromJSONLines[jsonUser](pipeline, strings.NewReader("{\"id\":1,\"name\":\"a\"}\n\n{\"id\":2,\"name\":\"b\"}\n"))
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []jsonUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on decode error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		reader, writer := io.Pipe()
+		goChannel := jpipe.FromJSONLines[jsonUser](pipeline, reader).ToGoChannel()
+
+		writer.Write([]byte("{\"id\":1}\n"))
+		assert.Equal(t, jsonUser{ID: 1}, <-goChannel)
+		writer.Write([]byte("{\"id\":\"X\"}\n")) // only fail once the first value has been read
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorContains(t, pipeline.Error(), "line 2")
+	})
+}
+
+func TestFromJSONLinesItems(t *testing.T) {
+	t.Run("Sends decode errors as errored items", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromJSONLinesItems[jsonUser](pipeline, strings.NewReader("{\"id\":1}\nnot json\n{\"id\":3}\n"))
+
+		values := drainChannel(channel)
+
+		assert.Len(t, values, 3)
+		assert.Equal(t, item.Item[jsonUser]{Value: jsonUser{ID: 1}}, values[0])
+		assert.ErrorContains(t, values[1].Error, "line 2")
+		assert.Equal(t, item.Item[jsonUser]{Value: jsonUser{ID: 3}}, values[2])
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
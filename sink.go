@@ -1,24 +1,118 @@
 package jpipe
 
 import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+
+	"github.com/junitechnology/jpipe/item"
 	"github.com/junitechnology/jpipe/options"
 )
 
 // ForEach calls the function passed as parameter for every value coming from the input channel.
 // The returned channel will close when all input values have been processed, or the pipeline is canceled.
 func (input *Channel[T]) ForEach(function func(T), opts ...options.ForEachOption) <-chan struct{} {
-	var worker worker[T, any] = func(node workerNode[T, any]) {
-		node.LoopInput(0, func(value T) bool {
-			function(value)
-			return true
-		})
+	var sinkFunction operatorFunc[T, any] = func(_ context.Context, value T) (any, error) {
+		function(value)
+		return nil, nil
 	}
-	worker = worker.Pooled(getPooledWorkerOptions(opts)...)
+	worker := sinkFunction.SinkWorker(getPooledWorkerOptions(opts)...)
 
 	node := newSinkPipelineNode("ForEach", input, worker, getNodeOptions(opts)...)
 	return node.Done()
 }
 
+// ForEachErr calls the function passed as parameter for every value coming from the input channel.
+// If the function returns an error, the pipeline is canceled with that error and no more values are processed.
+// The returned channel will close when all input values have been processed, or the pipeline is canceled.
+func (input *Channel[T]) ForEachErr(function func(T) error, opts ...options.ForEachOption) <-chan struct{} {
+	var sinkFunction operatorFunc[T, any] = func(_ context.Context, value T) (any, error) {
+		return nil, function(value)
+	}
+	worker := sinkFunction.SinkWorker(getPooledWorkerOptions(opts)...)
+
+	node := newSinkPipelineNode("ForEachErr", input, worker, getNodeOptions(opts)...)
+	return node.Done()
+}
+
+// ForEachCtx works like ForEachErr, but the function also receives a context.
+// The context is canceled when the pipeline is canceled or the operator quits, so it can be used to abort any I/O in the function.
+// It also expires after the duration set with the Timeout option, if any.
+func (input *Channel[T]) ForEachCtx(function func(context.Context, T) error, opts ...options.ForEachOption) <-chan struct{} {
+	var sinkFunction operatorFunc[T, any] = func(ctx context.Context, value T) (any, error) {
+		return nil, function(ctx, value)
+	}
+	worker := sinkFunction.SinkWorker(getPooledWorkerOptions(opts)...)
+
+	node := newSinkPipelineNode("ForEachCtx", input, worker, getNodeOptions(opts)...)
+	return node.Done()
+}
+
+// ForEachItems calls the function passed as parameter for the value of every item coming from the input channel.
+// The function is only called for items with no error. Errored items, as well as function errors, are handled according to the options.OnError option:
+//
+//  - FailFast(default): the pipeline is canceled with the first error, which is also sent to the returned channel.
+//  - SkipErrors: errored items are ignored, and nil is sent to the returned channel.
+//  - CollectErrors: all errors are joined with errors.Join, and sent to the returned channel. nil is sent if no error was found.
+//
+// With the Traced option, a span is started for every item.
+//
+// The returned channel receives a value when all input values have been processed, or the pipeline is canceled.
+func ForEachItems[T any](input *Channel[item.Item[T]], function func(T) error, opts ...options.ForEachItemsOption) <-chan error {
+	return forEachItems("ForEachItems", input, func(_ context.Context, value T) (any, error) { return nil, function(value) }, opts)
+}
+
+// ForEachItemsCtx works like ForEachItems, but the function also receives a context.
+// The context is canceled when the pipeline is canceled or the operator quits, and it expires after the duration set with the Timeout option, if any.
+// It also has the values of the item context, including the span started for the item with the Traced option, so the function can start child spans.
+func ForEachItemsCtx[T any](input *Channel[item.Item[T]], function func(context.Context, T) error, opts ...options.ForEachItemsOption) <-chan error {
+	return forEachItems("ForEachItemsCtx", input, func(ctx context.Context, value T) (any, error) { return nil, function(ctx, value) }, opts)
+}
+
+func forEachItems[T any](nodeType string, input *Channel[item.Item[T]], function operatorFunc[T, any], opts []options.ForEachItemsOption) <-chan error {
+	poolOpts := getPooledWorkerOptions(opts)
+	onError := getOptionOrDefault(opts, FailFast())
+	var lock sync.Mutex
+	var errs []error
+	sinkFunction := function.decorated(poolOpts)
+	traced := getOption[options.ForEachItemsOption, options.Traced](opts)
+	spanName := getOptionOrDefault(opts, Name(nodeType)).Name
+	var processor processor[item.Item[T], any] = func(ctx context.Context, it item.Item[T]) (any, bool, error) {
+		it, endSpan := startItemSpan(traced, spanName, it)
+		err := it.Error
+		if err == nil {
+			_, err = sinkFunction(itemContext(ctx, it.Ctx), it.Value)
+		}
+		endSpan(err)
+		if err == nil {
+			return nil, false, nil
+		}
+		if onError.Strategy == options.SKIP_ERRORS {
+			logDroppedItem(ctx, err)
+			return nil, false, nil
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		errs = append(errs, err)
+		if onError.Strategy == options.COLLECT_ERRORS {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	worker := processor.PooledWorker(poolOpts...)
+
+	node := newSinkPipelineNode(nodeType, input, worker, getNodeOptions(opts)...)
+	return resultChannel(node, func(ch chan error) {
+		if onError.Strategy == options.FAIL_FAST && len(errs) > 0 {
+			ch <- errs[0]
+			return
+		}
+		ch <- errors.Join(errs...)
+	})
+}
+
 // Reduce performs a stateful reduction of the input values.
 // The reducer receives the current state and the current value, and must return the new state.
 // The final state is sent to the returned channel when all input values have been processed, or the pipeline is canceled.
@@ -104,9 +198,67 @@ func ToMap[T any, K comparable](input *Channel[T], getKey func(T) K, opts ...opt
 //  input : 0--1--2--3--X
 //  output: 0--1--2--3--X
 func (input *Channel[T]) ToGoChannel() <-chan T {
+	return input.toGoChannel("ToGoChannel")
+}
+
+// ToSeq returns an iterator over all values from the input channel, to be consumed with a for-range loop.
+// Values are pulled from the pipeline as the loop consumes them, so the pipeline only progresses while iterating.
+// If the loop exits early, the pipeline is canceled without error. The iterator can only be consumed once.
+// The sink is created, and the pipeline started unless it is started manually, when ToSeq is called rather than when the loop starts.
+// So the returned iterator must be consumed, or the pipeline canceled, otherwise the pipeline blocks forever and its goroutines leak.
+//
+// Example:
+//
+//  for value := range input.ToSeq() {
+//    fmt.Println(value)
+//  }
+func (input *Channel[T]) ToSeq() iter.Seq[T] {
+	pipeline := input.getPipeline()
+	goChannel := input.toGoChannel("ToSeq")
+	return func(yield func(T) bool) {
+		for value := range goChannel {
+			if !yield(value) {
+				pipeline.Cancel(nil)
+				return
+			}
+		}
+	}
+}
+
+// ToSeq2 works like ToSeq, but if the pipeline fails, its error is yielded after the last value, along with the zero value of T.
+// Every other value is yielded with a nil error.
+// As with ToSeq, the returned iterator must be consumed, or the pipeline canceled, otherwise the pipeline blocks forever.
+//
+// Example:
+//
+//  for value, err := range input.ToSeq2() {
+//    if err != nil {
+//      return err
+//    }
+//    fmt.Println(value)
+//  }
+func (input *Channel[T]) ToSeq2() iter.Seq2[T, error] {
+	pipeline := input.getPipeline()
+	goChannel := input.toGoChannel("ToSeq2")
+	return func(yield func(T, error) bool) {
+		for value := range goChannel {
+			if !yield(value, nil) {
+				pipeline.Cancel(nil)
+				return
+			}
+		}
+		if err := pipeline.Error(); err != nil { // the pipeline error is set before the Go channel closes
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+func (input *Channel[T]) toGoChannel(nodeType string) <-chan T {
 	goChannel := make(chan T)
 	worker := func(node workerNode[T, any]) {
 		node.LoopInput(0, func(value T) bool {
+			defer node.EndSending(node.StartSending()) // tracked like any other send, to detect the Go channel not being read
 			select {
 			case <-node.QuitSignal():
 				return false // the nested select gives priority to the quit signal, so we always exit early if needed
@@ -121,7 +273,7 @@ func (input *Channel[T]) ToGoChannel() <-chan T {
 		})
 	}
 
-	node := newSinkPipelineNode("ToGoChannel", input, worker)
+	node := newSinkPipelineNode(nodeType, input, worker)
 	go func() {
 		<-node.Done()
 		close(goChannel)
@@ -181,6 +333,25 @@ func (input *Channel[T]) Count() <-chan int64 {
 	return resultChannel(node, func(ch chan int64) { ch <- count })
 }
 
+// Discard reads and drops all values coming from the input channel.
+// It marks a channel as intentionally unused, e.g. an output of Broadcast, so the pipeline does not block on it and passes validation.
+// The returned channel will close when all input values have been read, or the pipeline is canceled.
+//
+// Example:
+//
+//  output := input.Discard()
+//
+//  input : 0--1--2--3--X
+//  output: ------------X
+func (input *Channel[T]) Discard(opts ...options.DiscardOption) <-chan struct{} {
+	worker := func(node workerNode[T, any]) {
+		node.LoopInput(0, func(value T) bool { return true })
+	}
+
+	node := newSinkPipelineNode("Discard", input, worker, getNodeOptions(opts)...)
+	return node.Done()
+}
+
 // Any determines if any input value matches the predicate.
 // If no value matches the predicate, false is sent to the returned channel when all input values have been processed, or the pipeline is canceled.
 // If instead some value is found to match the predicate, true is immediately sent to the returned channel and no more input values are read.
@@ -2,11 +2,15 @@ package jpipe_test
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/junitechnology/jpipe"
+	"github.com/junitechnology/jpipe/item"
+	"github.com/junitechnology/jpipe/tracing"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/exp/slices"
 )
@@ -65,6 +69,218 @@ func TestForEach(t *testing.T) {
 	})
 }
 
+func TestForEachErr(t *testing.T) {
+	t.Run("Processes all values in the channel", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+
+		values := []int{}
+		<-channel.ForEachErr(func(value int) error {
+			values = append(values, value)
+			return nil
+		})
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		goChannel := make(chan int)
+		channel := jpipe.FromGoChannel(pipeline, goChannel)
+		errTest := errors.New("test error")
+
+		values := []int{}
+		done := channel.ForEachErr(func(value int) error {
+			if value == 2 {
+				return errTest
+			}
+			values = append(values, value)
+			return nil
+		})
+		goChannel <- 1
+		goChannel <- 2
+
+		assertChannelClosed(t, done, 10*time.Millisecond)
+		assert.Equal(t, []int{1}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+}
+
+func TestForEachCtx(t *testing.T) {
+	t.Run("Processes all values in the channel", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+
+		values := []int{}
+		<-channel.ForEachCtx(func(ctx context.Context, value int) error {
+			values = append(values, value)
+			return ctx.Err()
+		})
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Context is canceled when the pipeline is canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		started := make(chan struct{})
+
+		done := channel.ForEachCtx(func(ctx context.Context, value int) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		<-started
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, done, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Context is canceled when the operator quits", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		canceled := make(chan struct{})
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3}).
+			TapCtx(func(ctx context.Context, value int) error {
+				if value == 1 {
+					<-ctx.Done()
+					close(canceled)
+				}
+				return nil
+			}, jpipe.Concurrent(2))
+
+		<-channel.Any(func(value int) bool { return value == 2 }) // Any quits and unsubscribes when it finds 2
+
+		assertChannelClosed(t, canceled, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
+
+func TestForEachItems(t *testing.T) {
+	errTest1 := errors.New("test error 1")
+	errTest2 := errors.New("test error 2")
+	newInput := func(pipeline *jpipe.Pipeline) *jpipe.Channel[item.Item[int]] {
+		return jpipe.FromSlice(pipeline, []item.Item[int]{
+			jpipe.ValueItem(1),
+			jpipe.ErrorItem[int](errTest1),
+			jpipe.ValueItem(3),
+			jpipe.ValueItem(4),
+		})
+	}
+	newFunction := func(values *[]int) func(int) error {
+		return func(value int) error {
+			if value == 3 {
+				return errTest2
+			}
+			*values = append(*values, value)
+			return nil
+		}
+	}
+
+	t.Run("Fails fast by default", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		values := []int{}
+
+		err := <-jpipe.ForEachItems(newInput(pipeline), newFunction(&values))
+
+		assert.Equal(t, []int{1}, values)
+		assert.ErrorIs(t, err, errTest1)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest1)
+	})
+
+	t.Run("Skips errors", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		values := []int{}
+
+		err := <-jpipe.ForEachItems(newInput(pipeline), newFunction(&values), jpipe.SkipErrors())
+
+		assert.Equal(t, []int{1, 4}, values)
+		assert.NoError(t, err)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Collects errors", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		values := []int{}
+
+		err := <-jpipe.ForEachItems(newInput(pipeline), newFunction(&values), jpipe.CollectErrors())
+
+		assert.Equal(t, []int{1, 4}, values)
+		assert.ErrorIs(t, err, errTest1)
+		assert.ErrorIs(t, err, errTest2)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Retries the function but not errored items", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		attempts := 0
+		function := func(value int) error {
+			attempts++
+			if value == 3 {
+				return errTest2
+			}
+			return nil
+		}
+
+		err := <-jpipe.ForEachItems(newInput(pipeline), function, jpipe.CollectErrors(), jpipe.Retry(2).WithBackoff(time.Millisecond, time.Millisecond, 1))
+
+		assert.Equal(t, 4, attempts) // 1 and 4 once, 3 twice, and the errored item never
+		assert.ErrorIs(t, err, errTest1)
+		assert.ErrorIs(t, err, errTest2)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Starts a span per item when traced", func(t *testing.T) {
+		recorder := tracing.NewRecorder()
+		pipeline := jpipe.New(context.TODO())
+		values := []int{}
+
+		err := <-jpipe.ForEachItems(newInput(pipeline), newFunction(&values), jpipe.CollectErrors(), jpipe.Traced(recorder))
+
+		assert.Error(t, err)
+		spans := recorder.Spans()
+		assert.Len(t, spans, 4)
+		assert.Equal(t, "ForEachItems", spans[0].Name)
+		assert.Empty(t, spans[0].Errors)
+		assert.Equal(t, []error{errTest1}, spans[1].Errors)
+		assert.Equal(t, []error{errTest2}, spans[2].Errors)
+		assert.Equal(t, 0, spans[0].ParentID)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+}
+
+func TestForEachItemsCtx(t *testing.T) {
+	t.Run("Passes the item span context to the function", func(t *testing.T) {
+		recorder := tracing.NewRecorder()
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []item.Item[int]{jpipe.ValueItem(1), jpipe.ValueItem(2)})
+
+		err := <-jpipe.ForEachItemsCtx(channel, func(ctx context.Context, value int) error {
+			_, span := recorder.Start(ctx, "child")
+			span.End()
+			return nil
+		}, jpipe.Traced(recorder))
+
+		assert.NoError(t, err)
+		sinkSpans := filterSpans(recorder.Spans(), "ForEachItemsCtx")
+		childSpans := filterSpans(recorder.Spans(), "child")
+		assert.Len(t, childSpans, 2)
+		assert.Equal(t, sinkSpans[0].ID, childSpans[0].ParentID)
+		assert.Equal(t, sinkSpans[1].ID, childSpans[1].ParentID)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+}
+
 func TestReduce(t *testing.T) {
 	t.Run("Reduces all values", func(t *testing.T) {
 		pipeline := jpipe.New(context.TODO())
@@ -125,6 +341,42 @@ func TestCount(t *testing.T) {
 	})
 }
 
+func TestDiscard(t *testing.T) {
+	t.Run("Reads all values", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channels := jpipe.FromSlice(pipeline, []int{1, 2, 3}).Broadcast(2)
+
+		channels[0].Discard()
+		values := <-channels[1].ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assert.NoError(t, pipeline.Error())
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Accepts node options", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+
+		<-jpipe.FromSlice(pipeline, []int{1, 2, 3}).Discard(jpipe.Name("ignored"))
+
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.Equal(t, "ignored", pipeline.Stats().Nodes[1].Name)
+		assert.Equal(t, int64(3), pipeline.Stats().Nodes[1].ItemsIn)
+	})
+
+	t.Run("Exits early on pipeline canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		goChannel := make(chan int)
+		done := jpipe.FromGoChannel(pipeline, goChannel).Discard()
+
+		goChannel <- 10
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, done, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+}
+
 func TestToSlice(t *testing.T) {
 	slice := []int{1, 2, 3}
 	pipeline := jpipe.New(context.TODO())
@@ -330,3 +582,88 @@ func TestNone(t *testing.T) {
 		assertPipelineDone(t, pipeline, 10*time.Millisecond)
 	})
 }
+
+func TestToSeq(t *testing.T) {
+	t.Run("Iterates over all values", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+
+		values := []int{}
+		for value := range channel.ToSeq() {
+			values = append(values, value)
+		}
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline if the loop exits early", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromGenerator(pipeline, func(i uint64) uint64 { return i })
+
+		values := []uint64{}
+		for value := range channel.ToSeq() {
+			values = append(values, value)
+			if value == 2 {
+				break
+			}
+		}
+
+		assert.Equal(t, []uint64{0, 1, 2}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
+
+func TestToSeq2(t *testing.T) {
+	t.Run("Iterates over all values", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+
+		values := []int{}
+		for value, err := range channel.ToSeq2() {
+			assert.NoError(t, err)
+			values = append(values, value)
+		}
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Yields the pipeline error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		goChannel := make(chan string, 1)
+		goChannel <- "1"
+		channel := jpipe.MapErr(jpipe.FromGoChannel(pipeline, goChannel), strconv.Atoi)
+
+		values := []int{}
+		var errs []error
+		for value, err := range channel.ToSeq2() {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			values = append(values, value)
+			goChannel <- "A" // only fail once the first value has been read
+		}
+
+		assert.Equal(t, []int{1}, values)
+		assert.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], strconv.ErrSyntax)
+	})
+
+	t.Run("Cancels pipeline if the loop exits early", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromGenerator(pipeline, func(i uint64) uint64 { return i })
+
+		for value := range channel.ToSeq2() {
+			if value == 2 {
+				break
+			}
+		}
+
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
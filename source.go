@@ -1,15 +1,38 @@
 package jpipe
 
 import (
+	"context"
+	"iter"
+	"sync/atomic"
+
+	"github.com/junitechnology/jpipe/options"
 	"golang.org/x/exp/constraints"
 )
 
 // FromGoChannel creates a Channel from a Go channel
 func FromGoChannel[T any](pipeline *Pipeline, channel <-chan T) *Channel[T] {
 	worker := func(node workerNode[any, T]) {
-		loopOverChannel(node, channel, func(value T) bool {
-			return node.Send(value)
-		})
+		for produce(node) {
+			paused, _ := node.PauseSignals()
+			select { // the nested select gives priority to the quit and drain signals, so we always exit early if needed
+			case <-node.QuitSignal():
+				return
+			case <-node.DrainSignal():
+				return
+			default:
+				select {
+				case <-node.QuitSignal():
+					return
+				case <-node.DrainSignal():
+					return
+				case <-paused: // stop pulling values, produce will block until the pipeline is resumed
+				case value, open := <-channel:
+					if !open || !node.Send(value) {
+						return
+					}
+				}
+			}
+		}
 	}
 
 	_, output := newSourcePipelineNode("FromGoChannel", pipeline, worker)
@@ -21,7 +44,7 @@ func FromGoChannel[T any](pipeline *Pipeline, channel <-chan T) *Channel[T] {
 func FromSlice[T any](pipeline *Pipeline, slice []T) *Channel[T] {
 	worker := func(node workerNode[any, T]) {
 		for _, value := range slice {
-			if !node.Send(value) {
+			if !produce(node) || !node.Send(value) {
 				return
 			}
 		}
@@ -36,7 +59,7 @@ func FromSlice[T any](pipeline *Pipeline, slice []T) *Channel[T] {
 func FromRange[T constraints.Integer](pipeline *Pipeline, start T, end T) *Channel[T] {
 	worker := func(node workerNode[any, T]) {
 		for i := start; i <= end; i++ {
-			if !node.Send(i) {
+			if !produce(node) || !node.Send(i) {
 				return
 			}
 		}
@@ -48,15 +71,235 @@ func FromRange[T constraints.Integer](pipeline *Pipeline, start T, end T) *Chann
 
 // FromGenerator creates a Channel from a stateless generator function.
 // Values returned by the function are sent to the channel in order.
-func FromGenerator[T any](pipeline *Pipeline, generator func(i uint64) T) *Channel[T] {
+// With the Concurrent option, the function is called concurrently for consecutive values of i, so values may be sent out of order.
+func FromGenerator[T any](pipeline *Pipeline, generator func(i uint64) T, opts ...options.GeneratorOption) *Channel[T] {
+	var next atomic.Uint64
+	var worker worker[any, T] = func(node workerNode[any, T]) {
+		for produce(node) {
+			if !node.Send(generator(next.Add(1) - 1)) {
+				return
+			}
+		}
+	}
+
+	_, output := newSourcePipelineNode("FromGenerator", pipeline, worker.Pooled(getPooledWorkerOptions(opts)...), getNodeOptions(opts)...)
+	return output
+}
+
+// FromIterator creates a Channel from a stateful iterator function.
+// The function is called repeatedly, and the values it returns are sent to the channel in order, until it returns false.
+// If the function returns an error, the pipeline is canceled with that error.
+// With the Concurrent option, the function is called concurrently, so it must be safe for concurrent use, and values may be sent out of order.
+// No more calls are started once any call returns false.
+//
+// Example:
+//
+//  scanner := bufio.NewScanner(reader)
+//  lines := FromIterator(pipeline, func() (string, bool, error) {
+//    if !scanner.Scan() {
+//      return "", false, scanner.Err()
+//    }
+//    return scanner.Text(), true, nil
+//  })
+func FromIterator[T any](pipeline *Pipeline, iterator func() (T, bool, error), opts ...options.GeneratorOption) *Channel[T] {
+	var exhausted atomic.Bool
+	var worker worker[any, T] = func(node workerNode[any, T]) {
+		for !exhausted.Load() && produce(node) {
+			value, ok, err := iterator()
+			if err != nil {
+				node.Cancel(err)
+				return
+			}
+			if !ok {
+				exhausted.Store(true)
+				return
+			}
+			if !node.Send(value) {
+				return
+			}
+		}
+	}
+
+	_, output := newSourcePipelineNode("FromIterator", pipeline, worker.Pooled(getPooledWorkerOptions(opts)...), getNodeOptions(opts)...)
+	return output
+}
+
+// Unfold creates a Channel by repeatedly applying a function to a state, starting with seed.
+// Every call returns a value to send to the channel and the state for the next call, until the function returns false.
+//
+// Example:
+//
+//  output := Unfold(pipeline, 1, func(n int) (int, int, bool) { return n, n * 2, n <= 8 })
+//
+//  output: 1--2--4--8--X
+func Unfold[T any, S any](pipeline *Pipeline, seed S, function func(S) (T, S, bool), opts ...options.UnfoldOption) *Channel[T] {
+	worker := func(node workerNode[any, T]) {
+		state := seed
+		for produce(node) {
+			value, nextState, ok := function(state)
+			if !ok || !node.Send(value) {
+				return
+			}
+			state = nextState
+		}
+	}
+
+	_, output := newSourcePipelineNode("Unfold", pipeline, worker, getNodeOptions(opts)...)
+	return output
+}
+
+// FromPages creates a Channel from a paginated listing, e.g. a REST or gRPC API, sending the values of every page in order.
+// fetch is first called with the zero value of C as cursor, and must return the values in the page, the cursor of the next page,
+// and whether there are more pages. The context passed to fetch is canceled when the operator quits.
+// If fetch returns an error, the pipeline is canceled with that error.
+//
+// Pages are fetched concurrently with sending values downstream, up to a number of pages ahead set with the Prefetch option, which is 1 by default.
+//
+// Example:
+//
+//  users := FromPages(pipeline, func(ctx context.Context, pageToken string) ([]User, string, bool, error) {
+//    resp, err := client.ListUsers(ctx, &ListUsersRequest{PageToken: pageToken})
+//    if err != nil {
+//      return nil, "", false, err
+//    }
+//    return resp.Users, resp.NextPageToken, resp.NextPageToken != "", nil
+//  }, jpipe.Prefetch(2))
+func FromPages[C any, T any](pipeline *Pipeline, fetch func(ctx context.Context, cursor C) ([]T, C, bool, error), opts ...options.FromPagesOption) *Channel[T] {
+	prefetch := getOptionOrDefault(opts, Prefetch(1))
+	worker := func(node workerNode[any, T]) {
+		ctx, cancel := context.WithCancel(node.Context())
+		defer cancel()
+
+		var cursor C
+		more := true
+		fetchNext := func() ([]T, bool) {
+			if !more || !produce(node) {
+				return nil, false
+			}
+			page, nextCursor, nextMore, err := fetch(ctx, cursor)
+			if err != nil {
+				if ctx.Err() == nil { // otherwise the error is most likely caused by the operator quitting
+					node.Cancel(err)
+				}
+				return nil, false
+			}
+			cursor, more = nextCursor, nextMore
+			return page, true
+		}
+
+		nextPage := fetchNext
+		if prefetch.Depth > 0 {
+			pages := make(chan []T, prefetch.Depth-1) // the page held by the fetching goroutine while blocked is also prefetched
+			go func() {
+				defer close(pages)
+				defer node.HandlePanic()
+				for page, ok := fetchNext(); ok; page, ok = fetchNext() {
+					select {
+					case <-ctx.Done():
+						return
+					case pages <- page:
+					}
+				}
+			}()
+			defer func() {
+				cancel()
+				for range pages { // wait for the fetching goroutine to exit
+				}
+			}()
+			nextPage = func() ([]T, bool) {
+				page, ok := <-pages
+				return page, ok
+			}
+		}
+
+		for {
+			page, ok := nextPage()
+			if !ok {
+				return
+			}
+			for _, value := range page { // pages already fetched are sent even if the pipeline is drained, so no value is lost
+				if !waitResumed(node) || !node.Send(value) {
+					return
+				}
+			}
+		}
+	}
+
+	_, output := newSourcePipelineNode("FromPages", pipeline, worker, getNodeOptions(opts)...)
+	return output
+}
+
+// FromSeq creates a Channel from an iterator.
+// Values yielded by the iterator are sent to the channel in order.
+// The iterator stops being pulled as soon as the pipeline is canceled or drained, but a blocked iterator can't be interrupted.
+func FromSeq[T any](pipeline *Pipeline, seq iter.Seq[T]) *Channel[T] {
 	worker := func(node workerNode[any, T]) {
-		for i := uint64(0); ; i++ {
-			if !node.Send(generator(i)) {
+		if !produce(node) {
+			return
+		}
+		for value := range seq {
+			if !node.Send(value) || !produce(node) { // checked before pulling the next value, so a pulled value is never lost
 				return
 			}
 		}
 	}
 
-	_, output := newSourcePipelineNode("FromGenerator", pipeline, worker)
+	_, output := newSourcePipelineNode("FromSeq", pipeline, worker)
 	return output
 }
+
+// FromSeq2 creates a Channel from an iterator that may fail.
+// Values yielded by the iterator are sent to the channel in order.
+// If the iterator yields an error, the pipeline is canceled with that error and the iterator is not pulled anymore.
+// The iterator stops being pulled as soon as the pipeline is canceled or drained, but a blocked iterator can't be interrupted.
+func FromSeq2[T any](pipeline *Pipeline, seq iter.Seq2[T, error]) *Channel[T] {
+	worker := func(node workerNode[any, T]) {
+		for value, err := range seq {
+			if err != nil {
+				node.Cancel(err)
+				return
+			}
+			if !node.Send(value) || !produce(node) { // checked before pulling the next value, so a pulled value is never lost
+				return
+			}
+		}
+	}
+
+	_, output := newSourcePipelineNode("FromSeq2", pipeline, worker)
+	return output
+}
+
+// produce returns whether a source node can produce another value.
+// It returns false if the node must quit or the pipeline is being drained, and it blocks while the pipeline is paused.
+// Sources must call it before pulling the next value from wherever they read it, as values already pulled must be sent even if the pipeline is drained.
+func produce[R any](node workerNode[any, R]) bool {
+	select { // the first select gives priority to the quit and drain signals, so we always exit early if needed
+	case <-node.QuitSignal():
+		return false
+	case <-node.DrainSignal():
+		return false
+	default:
+	}
+
+	_, resumed := node.PauseSignals()
+	select {
+	case <-node.QuitSignal():
+		return false
+	case <-node.DrainSignal():
+		return false
+	case <-resumed:
+		return true
+	}
+}
+
+// waitResumed blocks while the pipeline is paused, and returns false if the node must quit.
+// Unlike produce, it ignores the drain signal, so sources can use it before sending values they already pulled.
+func waitResumed[R any](node workerNode[any, R]) bool {
+	_, resumed := node.PauseSignals()
+	select {
+	case <-node.QuitSignal():
+		return false
+	case <-resumed:
+		return true
+	}
+}
@@ -2,12 +2,16 @@ package jpipe_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/junitechnology/jpipe"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
 )
 
 func TestFromSlice(t *testing.T) {
@@ -81,3 +85,321 @@ func TestFromGenerator(t *testing.T) {
 		assertPipelineDone(t, pipeline, 10*time.Millisecond)
 	})
 }
+
+// newConcurrencyGate returns a function that blocks until n calls to it are running at the same time, or a second passes,
+// and a function returning the peak number of calls that have been running at the same time
+func newConcurrencyGate(n int64) (wait func(), peak func() int64) {
+	var active, maxActive atomic.Int64
+	var once sync.Once
+	release := make(chan struct{})
+	wait = func() {
+		running := active.Add(1)
+		defer active.Add(-1)
+		for current := maxActive.Load(); running > current && !maxActive.CompareAndSwap(current, running); current = maxActive.Load() {
+		}
+		if running == n {
+			once.Do(func() { close(release) })
+		}
+		select {
+		case <-release:
+		case <-time.After(time.Second):
+		}
+	}
+	return wait, maxActive.Load
+}
+
+func TestFromGeneratorConcurrent(t *testing.T) {
+	t.Run("Calls generator concurrently", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		wait, peak := newConcurrencyGate(5)
+		channel := jpipe.FromGenerator(pipeline, func(i uint64) uint64 {
+			wait()
+			return i
+		}, jpipe.Concurrent(5)).Take(10)
+
+		values := drainChannel(channel)
+
+		slices.Sort(values)
+		assert.Equal(t, []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, values)
+		assert.Equal(t, int64(5), peak())
+		assertPipelineDone(t, pipeline, 100*time.Millisecond)
+	})
+}
+
+func TestFromIterator(t *testing.T) {
+	t.Run("Creates channel from iterator until it is exhausted", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		i := 0
+		channel := jpipe.FromIterator(pipeline, func() (int, bool, error) {
+			i++
+			return i, i <= 3, nil
+		})
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		errTest := errors.New("test error")
+		i := 0
+		channel := jpipe.FromIterator(pipeline, func() (int, bool, error) {
+			i++
+			if i == 3 {
+				return 0, false, errTest
+			}
+			return i, true, nil
+		})
+
+		values := drainChannel(channel)
+
+		assert.Subset(t, []int{1, 2}, values) // the error may cancel the pipeline before all previous values are read
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+
+	t.Run("Exits early if pipeline canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromIterator(pipeline, func() (int, bool, error) { return 1, true, nil })
+		goChannel := channel.ToGoChannel()
+
+		readGoChannel(goChannel, 2)
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Calls iterator concurrently", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		next := atomic.Int64{}
+		wait, peak := newConcurrencyGate(5)
+		channel := jpipe.FromIterator(pipeline, func() (int64, bool, error) {
+			wait()
+			i := next.Add(1)
+			return i, i <= 10, nil
+		}, jpipe.Concurrent(5))
+
+		values := drainChannel(channel)
+
+		slices.Sort(values)
+		assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, values)
+		assert.Equal(t, int64(5), peak())
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+}
+
+func TestUnfold(t *testing.T) {
+	t.Run("Creates channel from state transitions", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.Unfold(pipeline, [2]int{0, 1}, func(fib [2]int) (int, [2]int, bool) {
+			return fib[0], [2]int{fib[1], fib[0] + fib[1]}, fib[0] < 10
+		})
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []int{0, 1, 1, 2, 3, 5, 8}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Accepts node options", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.Unfold(pipeline, 1, func(n int) (int, int, bool) { return n, n + 1, n <= 3 }, jpipe.Name("counter"))
+
+		assert.Equal(t, []int{1, 2, 3}, drainChannel(channel))
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.Equal(t, "counter", pipeline.Stats().Nodes[0].Name)
+	})
+
+	t.Run("Exits early if pipeline canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.Unfold(pipeline, 0, func(n int) (int, int, bool) { return n, n + 1, true })
+		goChannel := channel.ToGoChannel()
+
+		assert.Equal(t, []int{0, 1}, readGoChannel(goChannel, 2))
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+}
+
+func TestFromPages(t *testing.T) {
+	pages := [][]int{{1, 2}, {3, 4}, {5}}
+	fetchPage := func(fetched *atomic.Int64) func(ctx context.Context, cursor int) ([]int, int, bool, error) {
+		return func(ctx context.Context, cursor int) ([]int, int, bool, error) {
+			fetched.Add(1)
+			return pages[cursor], cursor + 1, cursor+1 < len(pages), nil
+		}
+	}
+
+	t.Run("Sends the values of all pages in order", func(t *testing.T) {
+		for _, depth := range []int{0, 1, 3} {
+			pipeline := jpipe.New(context.TODO())
+			channel := jpipe.FromPages(pipeline, fetchPage(&atomic.Int64{}), jpipe.Prefetch(depth))
+
+			values := drainChannel(channel)
+
+			assert.Equal(t, []int{1, 2, 3, 4, 5}, values)
+			assertPipelineDone(t, pipeline, 10*time.Millisecond)
+			assert.NoError(t, pipeline.Error())
+		}
+	})
+
+	t.Run("Prefetches up to the given depth", func(t *testing.T) {
+		for _, depth := range []int{0, 1, 2} {
+			pipeline := jpipe.New(context.TODO())
+			fetched := atomic.Int64{}
+			channel := jpipe.FromPages(pipeline, func(ctx context.Context, cursor int) ([]int, int, bool, error) {
+				fetched.Add(1)
+				return make([]int, 10), cursor + 1, true, nil // pages are big enough not to be fully sent while reading a value
+			}, jpipe.Prefetch(depth))
+			goChannel := channel.ToGoChannel()
+
+			readGoChannel(goChannel, 1) // the rest of the first page is never read, so FromPages blocks sending it
+			assert.Eventually(t, func() bool { return fetched.Load() == int64(1+depth) }, time.Second, time.Millisecond)
+			assert.Never(t, func() bool { return fetched.Load() > int64(1+depth) }, 20*time.Millisecond, time.Millisecond)
+
+			cancelPipeline(pipeline)
+			assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		}
+	})
+
+	t.Run("Fetches pages concurrently with sending values", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		fetched := atomic.Int64{}
+		channel := jpipe.FromPages(pipeline, func(ctx context.Context, cursor int) ([]int, int, bool, error) {
+			fetched.Add(1)
+			return []int{cursor}, cursor + 1, cursor < 3, nil
+		})
+		release := make(chan struct{})
+
+		values := []int{}
+		done := channel.ForEach(func(value int) {
+			if value == 0 {
+				<-release
+			}
+			values = append(values, value)
+		})
+		// while the first value is processed, FromPages fetches the second page to send it, and prefetches the third one.
+		// Without prefetching, the third page would only be fetched after the second one is sent.
+		assert.Eventually(t, func() bool { return fetched.Load() == 3 }, time.Second, time.Millisecond)
+		close(release)
+		<-done
+
+		assert.Equal(t, []int{0, 1, 2, 3}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Cancels pipeline on fetch error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		errTest := errors.New("test error")
+		channel := jpipe.FromPages(pipeline, func(ctx context.Context, cursor int) ([]int, int, bool, error) {
+			if cursor == 1 {
+				return nil, 0, false, errTest
+			}
+			return []int{1, 2}, cursor + 1, true, nil
+		})
+
+		values := drainChannel(channel)
+
+		assert.Subset(t, []int{1, 2}, values) // the error may cancel the pipeline before all previous values are read
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+
+	t.Run("Exits early if pipeline canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		fetchCtx := make(chan context.Context, 1)
+		channel := jpipe.FromPages(pipeline, func(ctx context.Context, cursor int) ([]int, int, bool, error) {
+			if cursor == 1 {
+				fetchCtx <- ctx
+				<-ctx.Done()
+				return nil, 0, false, ctx.Err()
+			}
+			return []int{1, 2}, cursor + 1, true, nil
+		})
+		goChannel := channel.ToGoChannel()
+
+		readGoChannel(goChannel, 1)
+		ctx := <-fetchCtx
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.Error(t, ctx.Err())
+		assert.NotErrorIs(t, pipeline.Error(), context.Canceled)
+	})
+}
+
+func TestFromSeq(t *testing.T) {
+	t.Run("Creates channel from iterator", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSeq(pipeline, func(yield func(int) bool) {
+			for i := 1; i <= 3 && yield(i); i++ {
+			}
+		})
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Stops pulling the iterator if pipeline canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		pulled := atomic.Int64{}
+		channel := jpipe.FromSeq(pipeline, func(yield func(int) bool) {
+			for i := 0; yield(i); i++ {
+				pulled.Add(1)
+			}
+		})
+		goChannel := channel.ToGoChannel()
+
+		readGoChannel(goChannel, 2)
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.LessOrEqual(t, pulled.Load(), int64(3))
+	})
+}
+
+func TestFromSeq2(t *testing.T) {
+	t.Run("Creates channel from iterator", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSeq2(pipeline, func(yield func(int, error) bool) {
+			for i := 1; i <= 3 && yield(i, nil); i++ {
+			}
+		})
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		errTest := errors.New("test error")
+		read := make(chan struct{})
+		channel := jpipe.FromSeq2(pipeline, func(yield func(int, error) bool) {
+			if yield(1, nil) {
+				<-read // only fail once the first value has been read
+				yield(0, errTest)
+			}
+		})
+		goChannel := channel.ToGoChannel()
+
+		assert.Equal(t, 1, <-goChannel)
+		close(read)
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+}
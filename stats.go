@@ -0,0 +1,167 @@
+package jpipe
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the buckets of latency histograms, plus an implicit last bucket with no upper bound
+var latencyBuckets = [...]time.Duration{
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+// Stats is a snapshot of the runtime metrics of a pipeline, as returned by [Pipeline.Stats]
+type Stats struct {
+	// Nodes contains the metrics of every operator in the pipeline, in creation order
+	Nodes []NodeStats
+}
+
+// NodeStats is a snapshot of the runtime metrics of an operator.
+// Durations are summed across the concurrent workers of the operator, so they can exceed the time the operator has been running.
+type NodeStats struct {
+	// ID identifies the operator within its pipeline, as in [GraphNode]
+	ID int
+	// Type is the type of the operator, e.g. "Map"
+	Type string
+	// Name is the name set with the Name option, if any
+	Name string
+	// ItemsIn is the number of values the operator has read from its inputs
+	ItemsIn int64
+	// ItemsOut is the number of values the operator has sent to its outputs
+	ItemsOut int64
+	// Latency is the histogram of durations of the calls to the operator function, for operators taking one, e.g. Map
+	Latency LatencyHistogram
+	// SendBlocked is the time the operator has spent sending values, i.e. waiting for downstream operators. A high value means backpressure.
+	SendBlocked time.Duration
+	// SendingSince is the time since the operator has been continuously sending values, or the zero time if it is not sending any.
+	// An operator sending for a long time is blocked by a downstream operator that doesn't read values.
+	SendingSince time.Time
+	// InputWait is the time the operator has spent waiting for input values. A high value means upstream operators are the bottleneck.
+	InputWait time.Duration
+	// WaitingSince is the time since the operator has been continuously waiting for input values, or the zero time if it is not waiting.
+	WaitingSince time.Time
+	// BufferSize is the total capacity of the output buffers of the operator
+	BufferSize int
+	// BufferLength is the number of values currently in the output buffers of the operator
+	BufferLength int
+}
+
+// A LatencyHistogram counts durations in buckets with fixed upper bounds, from 100µs to 10s
+type LatencyHistogram struct {
+	// Buckets contains the number of durations in every bucket. Counts are not cumulative.
+	Buckets []LatencyBucket
+	// Count is the total number of durations
+	Count int64
+	// Sum is the sum of all durations
+	Sum time.Duration
+}
+
+// A LatencyBucket counts the durations greater than the upper bound of the previous bucket, and less than or equal to its own.
+// The upper bound of the last bucket is math.MaxInt64, meaning it has no upper bound.
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      int64
+}
+
+// Mean returns the mean of all durations, or 0 if there are none
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// blockTracker tracks the time the workers of a node spend blocked in an operation, e.g. sending values
+type blockTracker struct {
+	lock    sync.Mutex
+	nanos   int64     // total time all workers have spent blocked
+	blocked int       // number of workers currently blocked
+	since   time.Time // time since there has been at least one worker blocked, or the zero time if there is none
+}
+
+// start records that a worker got blocked, and returns the current time to be passed to end
+func (b *blockTracker) start() time.Time {
+	now := time.Now()
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.blocked++
+	if b.blocked == 1 {
+		b.since = now
+	}
+	return now
+}
+
+// end records that a worker blocked since start is not blocked anymore
+func (b *blockTracker) end(start time.Time) {
+	elapsed := time.Since(start)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.blocked--
+	if b.blocked == 0 {
+		b.since = time.Time{}
+	}
+	b.nanos += int64(elapsed)
+}
+
+func (b *blockTracker) total() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return time.Duration(b.nanos)
+}
+
+// blockedSince returns the time since there has been at least one worker blocked, or the zero time if there is none
+func (b *blockTracker) blockedSince() time.Time {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.since
+}
+
+type latencyHistogram struct {
+	counts [len(latencyBuckets) + 1]atomic.Int64
+	sum    atomic.Int64
+}
+
+func (h *latencyHistogram) record(latency time.Duration) {
+	i := 0
+	for i < len(latencyBuckets) && latency > latencyBuckets[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.sum.Add(int64(latency))
+}
+
+func (h *latencyHistogram) snapshot() LatencyHistogram {
+	histogram := LatencyHistogram{Buckets: make([]LatencyBucket, len(h.counts)), Sum: time.Duration(h.sum.Load())}
+	for i := range h.counts {
+		upperBound := time.Duration(math.MaxInt64)
+		if i < len(latencyBuckets) {
+			upperBound = latencyBuckets[i]
+		}
+		count := h.counts[i].Load()
+		histogram.Buckets[i] = LatencyBucket{UpperBound: upperBound, Count: count}
+		histogram.Count += count
+	}
+
+	return histogram
+}
+
+// Stats returns a snapshot of the runtime metrics of every operator in the pipeline.
+// It is meant to find bottlenecks: the slowest operator usually has a high latency,
+// while the operators upstream have a high send blocked time and full buffers, and those downstream a high input wait time.
+// Operators created after the pipeline started, like the inner channels of FlatMap, are only included while they are running.
+func (p *Pipeline) Stats() Stats {
+	stats := Stats{Nodes: []NodeStats{}}
+	staticNodes, dynamicNodes := p.nodesSnapshot()
+	for _, node := range append(staticNodes, dynamicNodes...) {
+		stats.Nodes = append(stats.Nodes, node.Stats())
+	}
+
+	return stats
+}
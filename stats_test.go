@@ -0,0 +1,82 @@
+package jpipe_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/junitechnology/jpipe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineStats(t *testing.T) {
+	t.Run("Counts items and records latency", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		release := make(chan struct{})
+		channel := jpipe.Map(jpipe.FromRange(pipeline, 1, 5), func(i int) int {
+			if i == 1 {
+				<-release
+			}
+			time.Sleep(2 * time.Millisecond)
+			return i
+		}, jpipe.Name("slow"))
+		result := channel.ToSlice()
+		assert.Eventually(t, func() bool { // Map is blocked, so the source is blocked sending and the sink waiting for input
+			stats := pipeline.Stats()
+			return !stats.Nodes[0].SendingSince.IsZero() && !stats.Nodes[2].WaitingSince.IsZero()
+		}, time.Second, time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		close(release)
+		<-result
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+
+		stats := pipeline.Stats()
+
+		assert.Len(t, stats.Nodes, 3)
+		mapStats := stats.Nodes[1]
+		assert.Equal(t, 1, mapStats.ID)
+		assert.Equal(t, "Map", mapStats.Type)
+		assert.Equal(t, "slow", mapStats.Name)
+		assert.Equal(t, int64(5), mapStats.ItemsIn)
+		assert.Equal(t, int64(5), mapStats.ItemsOut)
+		assert.Equal(t, int64(5), mapStats.Latency.Count)
+		assert.GreaterOrEqual(t, mapStats.Latency.Mean(), 2*time.Millisecond)
+		assert.Len(t, mapStats.Latency.Buckets, 7)
+		assert.Equal(t, time.Millisecond, mapStats.Latency.Buckets[1].UpperBound)
+		assert.Equal(t, int64(0), mapStats.Latency.Buckets[0].Count+mapStats.Latency.Buckets[1].Count) // every call takes more than 1ms
+		assert.Equal(t, int64(5), stats.Nodes[0].ItemsOut)
+		assert.Equal(t, int64(0), stats.Nodes[0].Latency.Count)
+		assert.GreaterOrEqual(t, stats.Nodes[0].SendBlocked, 5*time.Millisecond) // the source was blocked sending while Map was blocked
+		assert.GreaterOrEqual(t, stats.Nodes[2].InputWait, 5*time.Millisecond)   // the sink was waiting for input while Map was blocked
+		assert.True(t, stats.Nodes[0].SendingSince.IsZero())
+		assert.True(t, stats.Nodes[2].WaitingSince.IsZero())
+	})
+
+	t.Run("Reports buffer occupancy", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		goChannel := jpipe.FromRange(pipeline, 1, 10).Buffer(3).ToGoChannel()
+		assert.Eventually(t, func() bool { // nobody reads from goChannel, so the buffer fills and both Buffer and ToGoChannel get blocked sending
+			stats := pipeline.Stats()
+			return stats.Nodes[1].BufferLength == 3 && !stats.Nodes[1].SendingSince.IsZero() && !stats.Nodes[2].SendingSince.IsZero()
+		}, time.Second, time.Millisecond)
+
+		stats := pipeline.Stats()
+
+		assert.Equal(t, 3, stats.Nodes[1].BufferSize)
+		assert.Equal(t, 3, stats.Nodes[1].BufferLength)
+		assert.Equal(t, 0, stats.Nodes[0].BufferSize)
+		assert.True(t, stats.Nodes[1].SendingSince.Before(time.Now()))
+		assert.True(t, stats.Nodes[2].SendingSince.Before(time.Now()))
+		cancelPipeline(pipeline)
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+	})
+}
+
+// BenchmarkStatsOverhead measures the cost of sending values between operators, including the tracking of blocked time
+func BenchmarkStatsOverhead(b *testing.B) {
+	pipeline := jpipe.New(context.TODO())
+	channel := jpipe.Map(jpipe.FromRange(pipeline, 1, b.N), func(i int) int { return i })
+
+	b.ResetTimer()
+	<-channel.ForEach(func(int) {})
+}
@@ -0,0 +1,111 @@
+// Package tracing defines the tracing integration of jpipe, which starts a span per item per operator.
+//
+// Item operators like MapItems start spans when given the jpipe.Traced option. Operator functions receiving a context, like in MapItemsCtx,
+// get the context of the span, so they can start child spans.
+// Every span is started with the context of its item as parent, and the item continues downstream with the context of the span,
+// so spans of successive operators are linked together, and to any span already in the item context.
+//
+// Tracer and Span are minimal interfaces, so they can be easily implemented on top of OpenTelemetry or any other tracing library.
+// Recorder is an in-memory implementation meant for tests.
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// A Tracer starts spans
+type Tracer interface {
+	// Start starts a span with the given name, as a child of the span in ctx, if any.
+	// It returns a context containing the new span, so it can be used as the parent of other spans.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// A Span represents the processing of an item by an operator
+type Span interface {
+	// RecordError records an error found while processing the item
+	RecordError(err error)
+	// End ends the span
+	End()
+}
+
+// A Recorder is a Tracer that keeps all spans in memory, so they can be inspected in tests.
+// It is safe to use from multiple goroutines.
+type Recorder struct {
+	lock   sync.Mutex
+	spans  []*recorderSpan
+	nextID int
+}
+
+// A RecordedSpan is a snapshot of a span started by a Recorder
+type RecordedSpan struct {
+	// ID identifies the span within its Recorder. IDs are assigned in start order, starting at 1
+	ID int
+	// ParentID is the ID of the parent span, or 0 if the span is a root span
+	ParentID int
+	// TraceID is the ID of the root span of the trace the span belongs to
+	TraceID int
+	Name    string
+	Errors  []error
+	Start   time.Time
+	// End is the time the span ended, or the zero time if it has not ended yet
+	End time.Time
+}
+
+type recorderSpan struct {
+	recorder *Recorder
+	span     RecordedSpan
+}
+
+type recorderSpanKey struct{}
+
+// NewRecorder returns a Recorder with no spans
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.nextID++
+	span := &recorderSpan{recorder: r, span: RecordedSpan{ID: r.nextID, TraceID: r.nextID, Name: name, Start: time.Now()}}
+	if parent, ok := ctx.Value(recorderSpanKey{}).(*recorderSpan); ok && parent.recorder == r {
+		span.span.ParentID = parent.span.ID
+		span.span.TraceID = parent.span.TraceID
+	}
+	r.spans = append(r.spans, span)
+
+	return context.WithValue(ctx, recorderSpanKey{}, span), span
+}
+
+// Spans returns a snapshot of all spans started so far, in start order
+func (r *Recorder) Spans() []RecordedSpan {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	spans := make([]RecordedSpan, len(r.spans))
+	for i := range r.spans {
+		spans[i] = r.spans[i].span
+		spans[i].Errors = append([]error{}, r.spans[i].span.Errors...)
+	}
+
+	return spans
+}
+
+func (s *recorderSpan) RecordError(err error) {
+	s.recorder.lock.Lock()
+	defer s.recorder.lock.Unlock()
+	s.span.Errors = append(s.span.Errors, err)
+}
+
+func (s *recorderSpan) End() {
+	s.recorder.lock.Lock()
+	defer s.recorder.lock.Unlock()
+	if s.span.End.IsZero() {
+		s.span.End = time.Now()
+	}
+}
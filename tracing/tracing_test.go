@@ -0,0 +1,48 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/junitechnology/jpipe/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Run("Links spans to the parent span in the context", func(t *testing.T) {
+		recorder := tracing.NewRecorder()
+		errTest := errors.New("test error")
+
+		ctx, root := recorder.Start(context.Background(), "root")
+		_, child := recorder.Start(ctx, "child")
+		_, other := recorder.Start(nil, "other")
+		child.RecordError(errTest)
+		child.End()
+		root.End()
+
+		spans := recorder.Spans()
+		assert.Len(t, spans, 3)
+		assert.Equal(t, "root", spans[0].Name)
+		assert.Equal(t, 0, spans[0].ParentID)
+		assert.Equal(t, 1, spans[0].TraceID)
+		assert.Equal(t, "child", spans[1].Name)
+		assert.Equal(t, spans[0].ID, spans[1].ParentID)
+		assert.Equal(t, spans[0].ID, spans[1].TraceID)
+		assert.Equal(t, []error{errTest}, spans[1].Errors)
+		assert.False(t, spans[1].End.IsZero())
+		assert.Equal(t, 0, spans[2].ParentID)
+		assert.Equal(t, spans[2].ID, spans[2].TraceID)
+		assert.True(t, spans[2].End.IsZero())
+		other.End()
+	})
+
+	t.Run("Ignores spans from other recorders", func(t *testing.T) {
+		ctx, _ := tracing.NewRecorder().Start(context.Background(), "root")
+		recorder := tracing.NewRecorder()
+
+		recorder.Start(ctx, "child")
+
+		assert.Equal(t, 0, recorder.Spans()[0].ParentID)
+	})
+}
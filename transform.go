@@ -1,6 +1,7 @@
 package jpipe
 
 import (
+	"context"
 	"time"
 
 	"github.com/junitechnology/jpipe/item"
@@ -16,15 +17,116 @@ import (
 //  input : 0--1--2--3--4--5--X
 //  output: 10-11-12-13-14-15-X
 func Map[T any, R any](input *Channel[T], mapper func(T) R, opts ...options.MapOption) *Channel[R] {
-	var processor processor[T, R] = func(value T) (R, bool) {
-		return mapper(value), true
+	var function operatorFunc[T, R] = func(_ context.Context, value T) (R, error) {
+		return mapper(value), nil
 	}
-	worker := processor.PooledWorker(getPooledWorkerOptions(opts)...)
+	worker := function.MapWorker(getPooledWorkerOptions(opts)...)
 
 	_, output := newLinearPipelineNode("Map", input, worker, getNodeOptions(opts)...)
 	return output
 }
 
+// MapErr transforms every input value with a mapper function that may fail, and sends the results to the output channel.
+// If the mapper returns an error, the pipeline is canceled with that error and no more values are processed.
+//
+// Example:
+//
+//  output := MapErr(input, func(s string) (int, error) { return strconv.Atoi(s) })
+//
+//  input : 1--2--3--A--5--6--X
+//  output: 1--2--3--X
+func MapErr[T any, R any](input *Channel[T], mapper func(T) (R, error), opts ...options.MapOption) *Channel[R] {
+	var function operatorFunc[T, R] = func(_ context.Context, value T) (R, error) {
+		return mapper(value)
+	}
+	worker := function.MapWorker(getPooledWorkerOptions(opts)...)
+
+	_, output := newLinearPipelineNode("MapErr", input, worker, getNodeOptions(opts)...)
+	return output
+}
+
+// MapCtx works like MapErr, but the mapper also receives a context.
+// The context is canceled when the pipeline is canceled or the operator quits, so it can be used to abort any I/O in the mapper.
+// It also expires after the duration set with the Timeout option, if any.
+//
+// Example:
+//
+//  output := MapCtx(input, func(ctx context.Context, id int) (User, error) { return fetchUser(ctx, id) }, Timeout(time.Second))
+func MapCtx[T any, R any](input *Channel[T], mapper func(context.Context, T) (R, error), opts ...options.MapOption) *Channel[R] {
+	var function operatorFunc[T, R] = mapper
+	worker := function.MapWorker(getPooledWorkerOptions(opts)...)
+
+	_, output := newLinearPipelineNode("MapCtx", input, worker, getNodeOptions(opts)...)
+	return output
+}
+
+// MapItems transforms the value of every input item with a mapper function that may fail, and sends the resulting items to the output channel.
+// The mapper is only called for items with no error. Errored items, as well as mapper errors, are handled according to the options.OnError option:
+//
+//  - ForwardErrors(default): errored items are sent to the output channel.
+//  - FailFast: the pipeline is canceled with the first error.
+//  - SkipErrors: errored items are dropped.
+//
+// It panics with CollectErrors, which is only meaningful for item sinks.
+// To send errored items to a separate dead-letter channel instead, forward them and pass the output to DeadLetter.
+//
+// The context of every input item is kept in the corresponding output item.
+// With the Traced option, a span is started for every item, and its context is set in the output item instead.
+//
+// Example:
+//
+//  output := MapItems(input, func(s string) (int, error) { return strconv.Atoi(s) })
+//
+//  input : 1--2--A--4--X
+//  output: 1--2--E--4--X
+//
+// Example with a dead-letter channel:
+//
+//  values, deadLetters := DeadLetter(MapItems(input, func(s string) (int, error) { return strconv.Atoi(s) }))
+func MapItems[T any, R any](input *Channel[item.Item[T]], mapper func(T) (R, error), opts ...options.MapItemsOption) *Channel[item.Item[R]] {
+	return mapItems("MapItems", input, func(_ context.Context, value T) (R, error) { return mapper(value) }, opts)
+}
+
+// MapItemsCtx works like MapItems, but the mapper also receives a context.
+// The context is canceled when the pipeline is canceled or the operator quits, and it expires after the duration set with the Timeout option, if any.
+// It also has the values of the item context, including the span started for the item with the Traced option, so the mapper can start child spans.
+//
+// Example:
+//
+//  output := MapItemsCtx(input, func(ctx context.Context, id int) (User, error) { return fetchUser(ctx, id) }, Traced(tracer))
+func MapItemsCtx[T any, R any](input *Channel[item.Item[T]], mapper func(context.Context, T) (R, error), opts ...options.MapItemsOption) *Channel[item.Item[R]] {
+	return mapItems("MapItemsCtx", input, mapper, opts)
+}
+
+func mapItems[T any, R any](nodeType string, input *Channel[item.Item[T]], mapper operatorFunc[T, R], opts []options.MapItemsOption) *Channel[item.Item[R]] {
+	poolOpts := getPooledWorkerOptions(opts)
+	onError := getOptionOrDefault(opts, ForwardErrors())
+	if onError.Strategy == options.COLLECT_ERRORS {
+		panic(nodeType + " can't collect errors, only item sinks can")
+	}
+	handleError := itemErrorProcessor[R](onError)
+	function := mapper.decorated(poolOpts)
+	traced := getOption[options.MapItemsOption, options.Traced](opts)
+	spanName := getOptionOrDefault(opts, Name(nodeType)).Name
+	var processor processor[item.Item[T], item.Item[R]] = func(ctx context.Context, it item.Item[T]) (item.Item[R], bool, error) {
+		it, endSpan := startItemSpan(traced, spanName, it)
+		if it.Error != nil {
+			endSpan(it.Error)
+			return handleError(ctx, it.Error, it.Ctx)
+		}
+		value, err := function(itemContext(ctx, it.Ctx), it.Value)
+		endSpan(err)
+		if err != nil {
+			return handleError(ctx, err, it.Ctx)
+		}
+		return item.Item[R]{Value: value, Ctx: it.Ctx}, true, nil
+	}
+	worker := processor.PooledWorker(poolOpts...)
+
+	_, output := newLinearPipelineNode(nodeType, input, worker, getNodeOptions(opts)...)
+	return output
+}
+
 // FlatMap transforms every input value into a Channel and for each of those, it sends all values to the output channel.
 //
 // Example:
@@ -37,6 +139,9 @@ func FlatMap[T any, R any](input *Channel[T], mapper func(T) *Channel[R], opts .
 	var worker worker[T, R] = func(node workerNode[T, R]) {
 		node.LoopInput(0, func(value T) bool {
 			mappedChannel := mapper(value)
+			if toNode, ok := node.(pipelineNode); ok {
+				mappedChannel.setGraphToNode(toNode)
+			}
 			for outputValue := range mappedChannel.getChannel() {
 				if !node.Send(outputValue) {
 					mappedChannel.unsubscribe()
@@ -91,6 +196,7 @@ func Batch[T any](input *Channel[T], size int, timeout time.Duration) *Channel[[
 						done = true
 						break
 					}
+					node.Received(value)
 					batch = append(batch, value)
 					if len(batch) == size {
 						flush = true
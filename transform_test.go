@@ -2,12 +2,16 @@ package jpipe_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/junitechnology/jpipe"
 	"github.com/junitechnology/jpipe/item"
+	"github.com/junitechnology/jpipe/tracing"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/exp/slices"
 )
@@ -88,6 +92,479 @@ func TestMap(t *testing.T) {
 		}
 		assertPipelineDone(t, pipeline, 10*time.Millisecond)
 	})
+
+	t.Run("Retries panics", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		attempts := map[int]int{}
+		mappedChannel := jpipe.Map(channel, func(i int) int {
+			attempts[i]++
+			if attempts[i] < 2 {
+				panic("flaky panic")
+			}
+			return i * 10
+		}, jpipe.Retry(2).WithBackoff(time.Millisecond, time.Millisecond, 1))
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Equal(t, []int{10, 20, 30}, mappedValues)
+		assert.Equal(t, map[int]int{1: 2, 2: 2, 3: 2}, attempts)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Propagates panic when retries are exhausted", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		attempts := 0
+		mappedChannel := jpipe.Map(channel, func(i int) int {
+			attempts++
+			panic("test panic")
+		}, jpipe.Retry(2).WithBackoff(time.Millisecond, time.Millisecond, 1))
+
+		mappedValues := <-mappedChannel.ToSlice()
+
+		assert.Empty(t, mappedValues)
+		assert.Equal(t, 2, attempts)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		var panicErr *jpipe.PanicError
+		assert.ErrorAs(t, pipeline.Error(), &panicErr)
+		assert.Equal(t, "test panic", panicErr.Value)
+		assert.Equal(t, "Map", panicErr.NodeType)
+	})
+}
+
+func TestMapErr(t *testing.T) {
+	t.Run("Maps values", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []string{"1", "2", "3"})
+		mappedChannel := jpipe.MapErr(channel, strconv.Atoi)
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Equal(t, []int{1, 2, 3}, mappedValues)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []string{"1", "2", "A", "4"})
+		mappedChannel := jpipe.MapErr(channel, strconv.Atoi)
+
+		mappedValues := <-mappedChannel.ToSlice()
+
+		assert.Equal(t, []int{1, 2}, mappedValues)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), strconv.ErrSyntax)
+		assert.NotContains(t, pipeline.Error().Error(), "goroutine") // no stacktrace for plain errors
+	})
+
+	t.Run("Cancels pipeline on error with ordered concurrency", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromRange(pipeline, 1, 100)
+		errTest := errors.New("test error")
+		mappedChannel := jpipe.MapErr(channel, func(i int) (int, error) {
+			time.Sleep(time.Millisecond)
+			if i == 50 {
+				return 0, errTest
+			}
+			return i, nil
+		}, jpipe.Concurrent(5), jpipe.Ordered(5))
+
+		mappedValues := <-mappedChannel.ToSlice()
+
+		assert.Less(t, len(mappedValues), 50)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+
+	t.Run("Retries failed calls", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		attempts := map[int]int{}
+		mappedChannel := jpipe.MapErr(channel, func(i int) (int, error) {
+			attempts[i]++
+			if attempts[i] < 3 {
+				return 0, errors.New("flaky error")
+			}
+			return i * 10, nil
+		}, jpipe.Retry(3).WithBackoff(time.Millisecond, 10*time.Millisecond, 2))
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Equal(t, []int{10, 20, 30}, mappedValues)
+		assert.Equal(t, map[int]int{1: 3, 2: 3, 3: 3}, attempts)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline when retries are exhausted", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		errTest := errors.New("test error")
+		attempts := 0
+		mappedChannel := jpipe.MapErr(channel, func(i int) (int, error) {
+			attempts++
+			return 0, errTest
+		}, jpipe.Retry(3).WithBackoff(time.Millisecond, 10*time.Millisecond, 2))
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Empty(t, mappedValues)
+		assert.Equal(t, 3, attempts)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+
+	t.Run("Does not retry non retryable errors", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []string{"1", "A"})
+		attempts := 0
+		mappedChannel := jpipe.MapErr(channel, func(s string) (int, error) {
+			attempts++
+			return strconv.Atoi(s)
+		}, jpipe.Retry(3).If(func(err error) bool { return !errors.Is(err, strconv.ErrSyntax) }))
+
+		<-mappedChannel.ToSlice()
+
+		assert.Equal(t, 2, attempts)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), strconv.ErrSyntax)
+	})
+
+	t.Run("Clamps retry jitter", func(t *testing.T) {
+		assert.Equal(t, 1.0, jpipe.Retry(3).WithJitter(2).Jitter)
+		assert.Equal(t, 0.0, jpipe.Retry(3).WithJitter(-1).Jitter)
+	})
+
+	t.Run("Stops retrying immediately if pipeline canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		called := make(chan struct{}, 1)
+		mappedChannel := jpipe.MapErr(channel, func(i int) (int, error) {
+			select {
+			case called <- struct{}{}:
+			default:
+			}
+			return 0, errors.New("flaky error")
+		}, jpipe.Retry(3).WithBackoff(time.Hour, time.Hour, 2))
+		goChannel := mappedChannel.ToGoChannel()
+
+		<-called // the first attempt failed, so the operator is waiting an hour to retry
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+}
+
+func TestMapCtx(t *testing.T) {
+	t.Run("Maps values with a context", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		mappedChannel := jpipe.MapCtx(channel, func(ctx context.Context, i int) (int, error) {
+			return i * 10, ctx.Err()
+		})
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Equal(t, []int{10, 20, 30}, mappedValues)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on timeout", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		mappedChannel := jpipe.MapCtx(channel, func(ctx context.Context, i int) (int, error) {
+			if i == 2 {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return i * 10, nil
+		}, jpipe.Timeout(10*time.Millisecond))
+
+		mappedValues := <-mappedChannel.ToSlice()
+
+		assert.Equal(t, []int{10}, mappedValues)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), jpipe.ErrTimeout)
+		assert.ErrorIs(t, pipeline.Error(), context.DeadlineExceeded)
+	})
+
+	t.Run("Times out functions ignoring the context", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		block := make(chan struct{})
+		defer close(block)
+		mappedChannel := jpipe.MapErr(channel, func(i int) (int, error) {
+			<-block
+			return i, nil
+		}, jpipe.Concurrent(2), jpipe.Ordered(2), jpipe.Timeout(10*time.Millisecond))
+
+		mappedValues := <-mappedChannel.ToSlice() // returns even though the calls never do
+
+		assert.Empty(t, mappedValues)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), jpipe.ErrTimeout)
+	})
+
+	t.Run("Retries timed out calls", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		attempts := int32(0)
+		mappedChannel := jpipe.MapCtx(channel, func(ctx context.Context, i int) (int, error) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return i * 10, nil
+		}, jpipe.Timeout(10*time.Millisecond), jpipe.Retry(2).WithBackoff(time.Millisecond, time.Millisecond, 1))
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Equal(t, []int{10, 20, 30}, mappedValues)
+		assert.Equal(t, int32(4), atomic.LoadInt32(&attempts))
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Frees the worker when a call ignoring the context times out", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.Wrap(jpipe.FromSlice(pipeline, []int{1, 2, 3}))
+		block := make(chan struct{})
+		defer close(block)
+		mappedChannel := jpipe.MapItems(channel, func(i int) (int, error) {
+			if i == 1 {
+				<-block // never returns while the test runs
+			}
+			return i * 10, nil
+		}, jpipe.Timeout(10*time.Millisecond))
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Len(t, mappedValues, 3)
+		assert.ErrorIs(t, mappedValues[0].Error, jpipe.ErrTimeout)
+		assert.Equal(t, item.Item[int]{Value: 20}, mappedValues[1])
+		assert.Equal(t, item.Item[int]{Value: 30}, mappedValues[2])
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Ignores context errors after downstream stops early", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3})
+		started := make(chan struct{})
+		mappedChannel := jpipe.MapCtx(channel, func(ctx context.Context, i int) (int, error) {
+			if i == 2 {
+				close(started)
+				<-ctx.Done() // canceled when the operator quits, as Any needs no more values
+				return 0, ctx.Err()
+			}
+			return i * 10, nil
+		})
+
+		found := <-mappedChannel.Any(func(i int) bool {
+			<-started // Any stops while the mapper is processing the next value
+			return i == 10
+		})
+
+		assert.True(t, found)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Handles timeouts with the error strategy", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.Wrap(jpipe.FromSlice(pipeline, []int{1, 2, 3}))
+		mappedChannel := jpipe.MapItems(channel, func(i int) (int, error) {
+			if i == 2 {
+				time.Sleep(50 * time.Millisecond)
+			}
+			return i * 10, nil
+		}, jpipe.Timeout(10*time.Millisecond))
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Len(t, mappedValues, 3)
+		assert.ErrorIs(t, mappedValues[1].Error, jpipe.ErrTimeout)
+		assert.Equal(t, 30, mappedValues[2].Value)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
+
+func TestMapItems(t *testing.T) {
+	errTest := errors.New("test error")
+	newInput := func(pipeline *jpipe.Pipeline) *jpipe.Channel[item.Item[string]] {
+		return jpipe.FromSlice(pipeline, []item.Item[string]{
+			jpipe.ValueItem("1"),
+			jpipe.ErrorItem[string](errTest),
+			jpipe.ValueItem("A"),
+			jpipe.ValueItem("4"),
+		})
+	}
+
+	t.Run("Forwards errors by default", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		mappedChannel := jpipe.MapItems(newInput(pipeline), strconv.Atoi)
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Len(t, mappedValues, 4)
+		assert.Equal(t, item.Item[int]{Value: 1}, mappedValues[0])
+		assert.ErrorIs(t, mappedValues[1].Error, errTest)
+		assert.ErrorIs(t, mappedValues[2].Error, strconv.ErrSyntax)
+		assert.Equal(t, item.Item[int]{Value: 4}, mappedValues[3])
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Skips errors", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		mappedChannel := jpipe.MapItems(newInput(pipeline), strconv.Atoi, jpipe.SkipErrors())
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Equal(t, []item.Item[int]{{Value: 1}, {Value: 4}}, mappedValues)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Fails fast", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		mappedChannel := jpipe.MapItems(newInput(pipeline), strconv.Atoi, jpipe.FailFast())
+
+		mappedValues := <-mappedChannel.ToSlice()
+
+		assert.Equal(t, []item.Item[int]{{Value: 1}}, mappedValues)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+
+	t.Run("Sends errors to dead-letter channel", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		mappedChannel, deadLetters := jpipe.DeadLetter(jpipe.MapItems(newInput(pipeline), strconv.Atoi))
+
+		deadLettersDone := deadLetters.ToSlice()
+		mappedValues := drainChannel(mappedChannel)
+		deadLetterValues := <-deadLettersDone
+
+		assert.Equal(t, []item.Item[int]{{Value: 1}, {Value: 4}}, mappedValues)
+		assert.Len(t, deadLetterValues, 2)
+		assert.ErrorIs(t, deadLetterValues[0].Error, errTest)
+		assert.ErrorIs(t, deadLetterValues[1].Error, strconv.ErrSyntax)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Panics when collecting errors", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+
+		assert.Panics(t, func() { jpipe.MapItems(newInput(pipeline), strconv.Atoi, jpipe.CollectErrors()) })
+	})
+
+	t.Run("Keeps item context", func(t *testing.T) {
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []item.Item[string]{jpipe.Item("1", nil, ctx)})
+		mappedChannel := jpipe.MapItems(channel, strconv.Atoi)
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Equal(t, "value", mappedValues[0].Ctx.Value(ctxKey{}))
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Starts a span per item when traced", func(t *testing.T) {
+		recorder := tracing.NewRecorder()
+		rootCtx, rootSpan := recorder.Start(context.Background(), "root")
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []item.Item[string]{
+			jpipe.Item("1", nil, rootCtx),
+			jpipe.Item("A", nil, rootCtx),
+		})
+		parsed := jpipe.MapItems(channel, strconv.Atoi, jpipe.Traced(recorder), jpipe.Name("parse"))
+		doubled := jpipe.MapItems(parsed, func(i int) (int, error) { return i * 2, nil }, jpipe.Traced(recorder))
+
+		mappedValues := drainChannel(doubled)
+		rootSpan.End()
+
+		assert.Equal(t, 2, mappedValues[0].Value)
+		spans := recorder.Spans()
+		assert.Len(t, spans, 5)
+		for _, span := range spans {
+			assert.Equal(t, 1, span.TraceID)
+			assert.False(t, span.End.IsZero())
+		}
+		parseSpans := filterSpans(spans, "parse")
+		assert.Len(t, parseSpans, 2)
+		assert.Equal(t, 1, parseSpans[0].ParentID)
+		assert.Empty(t, parseSpans[0].Errors)
+		assert.ErrorIs(t, parseSpans[1].Errors[0], strconv.ErrSyntax)
+		mapSpans := filterSpans(spans, "MapItems")
+		assert.Len(t, mapSpans, 2)
+		assert.Equal(t, parseSpans[0].ID, mapSpans[0].ParentID)
+		assert.Equal(t, parseSpans[1].ID, mapSpans[1].ParentID)
+		assert.ErrorIs(t, mapSpans[1].Errors[0], strconv.ErrSyntax) // errored items are recorded downstream too
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+}
+
+func TestMapItemsCtx(t *testing.T) {
+	t.Run("Passes the item span context to the mapper", func(t *testing.T) {
+		recorder := tracing.NewRecorder()
+		pipeline := jpipe.New(context.TODO())
+		channel := jpipe.FromSlice(pipeline, []item.Item[string]{jpipe.ValueItem("1"), jpipe.ValueItem("2")})
+		mappedChannel := jpipe.MapItemsCtx(channel, func(ctx context.Context, s string) (int, error) {
+			_, span := recorder.Start(ctx, "child")
+			defer span.End()
+			return strconv.Atoi(s)
+		}, jpipe.Traced(recorder))
+
+		mappedValues := drainChannel(mappedChannel)
+
+		assert.Equal(t, []int{1, 2}, []int{mappedValues[0].Value, mappedValues[1].Value})
+		mapSpans := filterSpans(recorder.Spans(), "MapItemsCtx")
+		childSpans := filterSpans(recorder.Spans(), "child")
+		assert.Len(t, childSpans, 2)
+		assert.Equal(t, mapSpans[0].ID, childSpans[0].ParentID)
+		assert.Equal(t, mapSpans[1].ID, childSpans[1].ParentID)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Context is canceled when the pipeline is canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		type ctxKey struct{}
+		itemCtx := context.WithValue(context.Background(), ctxKey{}, "value")
+		channel := jpipe.FromSlice(pipeline, []item.Item[int]{jpipe.Item(1, nil, itemCtx)})
+		values := make(chan any, 1)
+		mappedChannel := jpipe.MapItemsCtx(channel, func(ctx context.Context, i int) (int, error) {
+			values <- ctx.Value(ctxKey{})
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		goChannel := mappedChannel.ToGoChannel()
+
+		assert.Equal(t, "value", <-values)
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
+
+func filterSpans(spans []tracing.RecordedSpan, name string) []tracing.RecordedSpan {
+	filtered := []tracing.RecordedSpan{}
+	for _, span := range spans {
+		if span.Name == name {
+			filtered = append(filtered, span)
+		}
+	}
+	return filtered
 }
 
 func TestFlatMap(t *testing.T) {
@@ -1,6 +1,7 @@
 package jpipe
 
 import (
+	"context"
 	"time"
 
 	"github.com/junitechnology/jpipe/options"
@@ -22,13 +23,38 @@ func (input *Channel[T]) Buffer(n int) *Channel[T] {
 // Tap runs a function as a side effect for each input value, and then sends the input values transparently to the output channel.
 // A common use case is logging.
 func (input *Channel[T]) Tap(function func(T), opts ...options.TapOption) *Channel[T] {
-	var processor processor[T, T] = func(value T) (T, bool) {
+	var tapFunction operatorFunc[T, T] = func(_ context.Context, value T) (T, error) {
 		function(value)
-		return value, true
+		return value, nil
 	}
-	worker := processor.PooledWorker(getPooledWorkerOptions(opts)...)
+	worker := tapFunction.MapWorker(getPooledWorkerOptions(opts)...)
 
-	_, output := newLinearPipelineNode("Tap", input, worker)
+	_, output := newLinearPipelineNode("Tap", input, worker, getNodeOptions(opts)...)
+	return output
+}
+
+// TapErr runs a function that may fail as a side effect for each input value, and then sends the input values transparently to the output channel.
+// If the function returns an error, the pipeline is canceled with that error and no more values are processed.
+func (input *Channel[T]) TapErr(function func(T) error, opts ...options.TapOption) *Channel[T] {
+	var tapFunction operatorFunc[T, T] = func(_ context.Context, value T) (T, error) {
+		return value, function(value)
+	}
+	worker := tapFunction.MapWorker(getPooledWorkerOptions(opts)...)
+
+	_, output := newLinearPipelineNode("TapErr", input, worker, getNodeOptions(opts)...)
+	return output
+}
+
+// TapCtx works like TapErr, but the function also receives a context.
+// The context is canceled when the pipeline is canceled or the operator quits, so it can be used to abort any I/O in the function.
+// It also expires after the duration set with the Timeout option, if any.
+func (input *Channel[T]) TapCtx(function func(context.Context, T) error, opts ...options.TapOption) *Channel[T] {
+	var tapFunction operatorFunc[T, T] = func(ctx context.Context, value T) (T, error) {
+		return value, function(ctx, value)
+	}
+	worker := tapFunction.MapWorker(getPooledWorkerOptions(opts)...)
+
+	_, output := newLinearPipelineNode("TapCtx", input, worker, getNodeOptions(opts)...)
 	return output
 }
 
@@ -44,18 +70,16 @@ func (input *Channel[T]) Tap(function func(T), opts ...options.TapOption) *Chann
 //  output: 0----1----2----------3----4----5-X
 func (input *Channel[T]) Interval(interval func(value T) time.Duration) *Channel[T] {
 	worker := func(node workerNode[T, T]) {
-		timeout := time.After(0)
+		nextSend := time.Now()
 		node.LoopInput(0, func(value T) bool {
-			select {
-			case <-node.QuitSignal():
+			if !pausableSleep(node, time.Until(nextSend)) {
 				return false
-			case <-timeout:
 			}
 
 			if !node.Send(value) {
 				return false
 			}
-			timeout = time.After(interval(value))
+			nextSend = time.Now().Add(interval(value))
 			return true
 		})
 	}
@@ -63,3 +87,34 @@ func (input *Channel[T]) Interval(interval func(value T) time.Duration) *Channel
 	_, output := newLinearPipelineNode("Interval", input, worker)
 	return output
 }
+
+// pausableSleep waits for the given duration, not counting the time the pipeline is paused.
+// It also waits for the pipeline to be resumed if it is paused, even if the duration is zero.
+// It returns false if the node must quit.
+func pausableSleep[T any, R any](node workerNode[T, R], duration time.Duration) bool {
+	for {
+		_, resumed := node.PauseSignals()
+		select {
+		case <-node.QuitSignal():
+			return false
+		case <-resumed:
+		}
+		if duration <= 0 {
+			return true
+		}
+
+		paused, _ := node.PauseSignals()
+		start := time.Now()
+		timer := time.NewTimer(duration)
+		select {
+		case <-node.QuitSignal():
+			timer.Stop()
+			return false
+		case <-timer.C:
+			return true
+		case <-paused:
+			timer.Stop()
+			duration -= time.Since(start)
+		}
+	}
+}
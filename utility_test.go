@@ -2,6 +2,7 @@ package jpipe_test
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -154,6 +155,62 @@ func TestTap(t *testing.T) {
 	})
 }
 
+func TestTapErr(t *testing.T) {
+	t.Run("Runs function and passes values through", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		tapped := []int{}
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3}).
+			TapErr(func(i int) error {
+				tapped = append(tapped, i)
+				return nil
+			})
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assert.Equal(t, []int{1, 2, 3}, tapped)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		errTest := errors.New("test error")
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3}).
+			TapErr(func(i int) error {
+				if i == 2 {
+					return errTest
+				}
+				return nil
+			})
+
+		values := <-channel.ToSlice()
+
+		assert.Equal(t, []int{1}, values)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+}
+
+func TestTapCtx(t *testing.T) {
+	t.Run("Runs function and passes values through", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		tapped := []int{}
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3}).
+			TapCtx(func(ctx context.Context, i int) error {
+				tapped = append(tapped, i)
+				return ctx.Err()
+			})
+
+		values := drainChannel(channel)
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+		assert.Equal(t, []int{1, 2, 3}, tapped)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
+
 func TestInterval(t *testing.T) {
 	t.Run("Emits values with interval", func(t *testing.T) {
 		pipeline := jpipe.New(context.TODO())
@@ -170,8 +227,12 @@ func TestInterval(t *testing.T) {
 		t2 := time.Now()
 
 		assert.Equal(t, []int{1, 2, 3}, values)
-		assert.WithinDuration(t, t0.Add(100*time.Millisecond), t1, 20*time.Millisecond)
-		assert.WithinDuration(t, t1.Add(100*time.Millisecond), t2, 20*time.Millisecond)
+		// the interval starts when a value is sent, slightly before it is read, so the lower bounds have some margin.
+		// The upper bounds are generous, so a loaded machine doesn't make the test fail.
+		for _, gap := range []time.Duration{t1.Sub(t0), t2.Sub(t1)} {
+			assert.GreaterOrEqual(t, gap, 90*time.Millisecond)
+			assert.Less(t, gap, 300*time.Millisecond)
+		}
 
 		// We wait less than the interval. We want to assert it doesn't add an interval after the last value
 		assertPipelineDone(t, pipeline, 10*time.Millisecond)
@@ -189,4 +250,29 @@ func TestInterval(t *testing.T) {
 		assertChannelClosed(t, goChannel, 10*time.Millisecond)
 		assertPipelineDone(t, pipeline, 60*time.Millisecond)
 	})
+
+	t.Run("Interval does not elapse while the pipeline is paused", func(t *testing.T) {
+		observer := &recordingObserver{}
+		start := time.Now()
+		pipeline := jpipe.NewPipeline(jpipe.Config{Observer: observer})
+		channel := jpipe.FromSlice(pipeline, []int{1, 2, 3}).
+			Buffer(3).
+			Interval(func(value int) time.Duration { return 200 * time.Millisecond })
+		goChannel := channel.ToGoChannel()
+
+		<-goChannel
+		assert.Eventually(t, func() bool { // the source must have sent all values to the buffer, so the pause only affects the interval
+			return slices.Contains(observer.getEvents(), "sent FromSlice 3")
+		}, time.Second, time.Millisecond)
+		pipeline.Pause()
+		paused := time.Now()
+		assertChannelOpenButNoValue(t, goChannel, 100*time.Millisecond)
+		pausedFor := time.Since(paused)
+		pipeline.Resume()
+		value := <-goChannel
+
+		assert.Equal(t, 2, value)
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond+pausedFor) // a whole interval elapsed without counting the pause
+		cancelPipeline(pipeline)
+	})
 }
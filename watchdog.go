@@ -0,0 +1,129 @@
+package jpipe
+
+import (
+	"strings"
+	"time"
+)
+
+// A Watchdog detects stuck operators, i.e. operators blocked sending values for too long,
+// or waiting for input values for too long from an operator that is itself blocked sending values, e.g. to another output of a Broadcast.
+// It usually means that a Channel or a Go channel returned by a sink is not being read, so the pipeline never finishes.
+// Operators just waiting for input values are not reported, as they may be idle, e.g. behind a FromGoChannel source with no values.
+//
+// Stuck operators are reported as a *StuckError to the pipeline Observer if it implements StuckObserver, and to the pipeline Logger.
+// Every operator is reported once every time it gets stuck. Nothing is reported while the pipeline is paused,
+// and the time an operator was blocked before the pipeline was last resumed doesn't count.
+type Watchdog struct {
+	// Threshold is the time an operator must be blocked to be reported as stuck. The watchdog is disabled if it's 0.
+	Threshold time.Duration
+	// FailPipeline makes the pipeline be canceled with the first *StuckError found
+	FailPipeline bool
+}
+
+// A StuckObserver is an Observer that also gets the stuck operators found by the pipeline Watchdog
+type StuckObserver interface {
+	Observer
+	// OnStuck is called every time an operator gets stuck
+	OnStuck(err *StuckError)
+}
+
+// watch checks the pipeline for stuck operators until it's done
+func (p *Pipeline) watch(watchdog Watchdog) {
+	ticker := time.NewTicker(max(watchdog.Threshold/2, time.Millisecond))
+	defer ticker.Stop()
+
+	reported := map[int]time.Time{} // the blocked since time of every reported node, so they're only reported once while blocked
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+		p.lock.Lock()
+		paused, resumedAt := p.isPaused(), p.resumedAt
+		p.lock.Unlock()
+		if paused {
+			continue
+		}
+
+		stats := p.Stats()
+		sendingSince := map[int]time.Time{}
+		for _, node := range stats.Nodes {
+			sendingSince[node.ID] = node.SendingSince
+		}
+		graph := p.Graph()
+		for _, node := range stats.Nodes {
+			since, sending := node.SendingSince, true
+			if since.IsZero() && graph.upstreamSending(node.ID, sendingSince) {
+				since, sending = node.WaitingSince, false
+			}
+			if since.IsZero() || reported[node.ID].Equal(since) {
+				continue
+			}
+			start := since
+			if start.Before(resumedAt) { // the time the pipeline was paused doesn't count
+				start = resumedAt
+			}
+			if time.Since(start) < watchdog.Threshold {
+				continue
+			}
+			reported[node.ID] = since
+
+			err := &StuckError{
+				NodeType: node.Type,
+				NodeName: node.Name,
+				NodeID:   node.ID,
+				Path:     graph.path(node.ID),
+				Sending:  sending,
+				Duration: time.Since(start),
+			}
+			p.reportStuck(err, watchdog)
+		}
+	}
+}
+
+func (p *Pipeline) reportStuck(err *StuckError, watchdog Watchdog) {
+	if observer, ok := p.observer.(StuckObserver); ok {
+		observer.OnStuck(err)
+	}
+	if p.logger != nil {
+		p.logger.Warn("operator stuck", "node_type", err.NodeType, "node_id", err.NodeID, "error", err)
+	}
+	if watchdog.FailPipeline {
+		p.Cancel(err)
+	}
+}
+
+// upstreamSending returns whether any operator sending values to the given one is blocked sending.
+// An operator waiting for input values is only stuck if that's the case, e.g. the other outputs of a Broadcast are not being read.
+// Otherwise, it is just idle, e.g. reading from a FromGoChannel source with no values.
+func (g Graph) upstreamSending(id int, sendingSince map[int]time.Time) bool {
+	for _, edge := range g.Edges {
+		if edge.To == id && !sendingSince[edge.From].IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// path returns the chain of operators from a source to the given one, following the first input of every operator
+func (g Graph) path(id int) string {
+	labels := []string{}
+	visited := map[int]bool{}
+	for !visited[id] {
+		visited[id] = true
+		node, ok := g.nodeByID(id)
+		if !ok {
+			break
+		}
+		labels = append([]string{nodeLabel(node.Type, node.Name, node.ID)}, labels...)
+		for _, edge := range g.Edges {
+			if edge.To == id {
+				id = edge.From
+				break
+			}
+		}
+	}
+
+	return strings.Join(labels, " -> ")
+}
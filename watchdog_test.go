@@ -0,0 +1,139 @@
+package jpipe_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junitechnology/jpipe"
+	"github.com/stretchr/testify/assert"
+)
+
+type stuckObserver struct {
+	jpipe.BaseObserver
+	lock sync.Mutex
+	errs []*jpipe.StuckError
+}
+
+func (o *stuckObserver) OnStuck(err *jpipe.StuckError) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.errs = append(o.errs, err)
+}
+
+func (o *stuckObserver) getErrs() []*jpipe.StuckError {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return append([]*jpipe.StuckError{}, o.errs...)
+}
+
+func TestWatchdog(t *testing.T) {
+	t.Run("Reports operators blocked sending values", func(t *testing.T) {
+		observer := &stuckObserver{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Observer: observer, Watchdog: jpipe.Watchdog{Threshold: 20 * time.Millisecond}})
+		jpipe.FromSlice(pipeline, []int{1, 2, 3}).ToGoChannel() // never read
+
+		assert.Eventually(t, func() bool { return len(observer.getErrs()) == 2 }, time.Second, time.Millisecond)    // ToGoChannel, and the source behind it
+		assert.Never(t, func() bool { return len(observer.getErrs()) > 2 }, 100*time.Millisecond, time.Millisecond) // each is reported only once
+
+		errs := observer.getErrs()
+		var sinkErr *jpipe.StuckError
+		for _, err := range errs {
+			if err.NodeType == "ToGoChannel" {
+				sinkErr = err
+			}
+		}
+		if assert.NotNil(t, sinkErr) {
+			assert.Equal(t, 1, sinkErr.NodeID)
+			assert.True(t, sinkErr.Sending)
+			assert.Equal(t, "FromSlice (#0) -> ToGoChannel (#1)", sinkErr.Path)
+			assert.GreaterOrEqual(t, sinkErr.Duration, 20*time.Millisecond)
+			assert.Contains(t, sinkErr.Error(), "ToGoChannel (#1) stuck sending values for")
+		}
+		assert.False(t, pipeline.IsDone())
+		cancelPipeline(pipeline)
+	})
+
+	t.Run("Reports operators waiting for input values from an operator blocked sending", func(t *testing.T) {
+		observer := &stuckObserver{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Observer: observer, Watchdog: jpipe.Watchdog{Threshold: 20 * time.Millisecond}})
+		outputs := jpipe.FromSlice(pipeline, []int{1, 2, 3}).Broadcast(2)
+		outputs[0].ToGoChannel() // never read, so Broadcast gets blocked and the consumer starves
+		outputs[1].ForEach(func(i int) {}, jpipe.Name("consumer"))
+
+		var consumerErr *jpipe.StuckError
+		assert.Eventually(t, func() bool {
+			for _, err := range observer.getErrs() {
+				if err.NodeName == "consumer" {
+					consumerErr = err
+					return true
+				}
+			}
+			return false
+		}, time.Second, time.Millisecond)
+
+		if assert.NotNil(t, consumerErr) {
+			assert.False(t, consumerErr.Sending)
+			assert.Equal(t, `FromSlice (#0) -> Broadcast (#1) -> ForEach "consumer" (#3)`, consumerErr.Path)
+			assert.GreaterOrEqual(t, consumerErr.Duration, 20*time.Millisecond)
+		}
+		cancelPipeline(pipeline)
+	})
+
+	t.Run("Does not report idle operators waiting for input values", func(t *testing.T) {
+		observer := &stuckObserver{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Observer: observer, Watchdog: jpipe.Watchdog{Threshold: 10 * time.Millisecond}})
+		goChannel := make(chan int)
+		jpipe.FromGoChannel(pipeline, goChannel).ForEach(func(i int) {})
+
+		assert.Never(t, func() bool { return len(observer.getErrs()) > 0 }, 100*time.Millisecond, time.Millisecond)
+		close(goChannel)
+		assert.NoError(t, pipeline.Wait())
+	})
+
+	t.Run("Cancels the pipeline if FailPipeline is set", func(t *testing.T) {
+		pipeline := jpipe.NewPipeline(jpipe.Config{Watchdog: jpipe.Watchdog{Threshold: 10 * time.Millisecond, FailPipeline: true}})
+		goChannel := jpipe.FromSlice(pipeline, []int{1, 2, 3}).ToGoChannel()
+
+		err := pipeline.Wait()
+
+		var stuckErr *jpipe.StuckError
+		assert.True(t, errors.As(err, &stuckErr))
+		assertChannelClosed(t, goChannel, 10*time.Millisecond)
+	})
+
+	t.Run("Does not count the time the pipeline is paused", func(t *testing.T) {
+		observer := &stuckObserver{}
+		threshold := 100 * time.Millisecond
+		pipeline := jpipe.NewPipeline(jpipe.Config{Observer: observer, Watchdog: jpipe.Watchdog{Threshold: threshold}})
+		jpipe.FromSlice(pipeline, []int{1, 2, 3}).ToGoChannel() // never read
+		var sendingSince time.Time
+		assert.Eventually(t, func() bool {
+			sendingSince = pipeline.Stats().Nodes[1].SendingSince
+			return !sendingSince.IsZero()
+		}, time.Second, time.Millisecond)
+		pipeline.Pause()
+
+		assert.Eventually(t, func() bool { return time.Since(sendingSince) > 2*threshold }, time.Second, time.Millisecond)
+		assert.Empty(t, observer.getErrs())
+		resumedAt := time.Now()
+		pipeline.Resume()
+
+		assert.Eventually(t, func() bool { return len(observer.getErrs()) > 0 }, time.Second, time.Millisecond)
+		for _, err := range observer.getErrs() {
+			assert.LessOrEqual(t, err.Duration, time.Since(resumedAt)) // only the time since the pipeline was resumed counts
+		}
+		cancelPipeline(pipeline)
+	})
+
+	t.Run("Does not report operators that are not stuck", func(t *testing.T) {
+		observer := &stuckObserver{}
+		pipeline := jpipe.NewPipeline(jpipe.Config{Context: context.TODO(), Observer: observer, Watchdog: jpipe.Watchdog{Threshold: 10 * time.Millisecond}})
+		<-jpipe.FromRange(pipeline, 1, 20).Interval(func(i int) time.Duration { return time.Millisecond }).ToSlice()
+
+		assert.NoError(t, pipeline.Wait())
+		assert.Empty(t, observer.getErrs())
+	})
+}
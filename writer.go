@@ -0,0 +1,190 @@
+package jpipe
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/junitechnology/jpipe/options"
+)
+
+// ToWriter writes every value coming from the input channel to the writer as a line, formatted as with fmt.Print.
+// Output is buffered, and it can be flushed periodically with the FlushEvery option. The writer is not closed.
+// If writing fails, the pipeline is canceled with the error.
+// The returned channel will close when all input values have been written and the output has been flushed, or the pipeline is canceled.
+//
+// Example:
+//
+//  <-input.ToWriter(os.Stdout)
+//
+//  input : 0--1--2--3--X
+//  output: ------------X
+func (input *Channel[T]) ToWriter(writer io.Writer, opts ...options.WriterOption) <-chan struct{} {
+	buffered := bufio.NewWriter(writer)
+	write := func(value T) error {
+		_, err := fmt.Fprintln(buffered, value)
+		return err
+	}
+
+	return toWriter("ToWriter", input, nil, write, buffered.Flush, getFlushEvery(opts), getNodeOptions(opts))
+}
+
+// ToJSONLines writes every value coming from the input channel to the writer as a line in JSON Lines format.
+// Output is buffered, and it can be flushed periodically with the FlushEvery option. The writer is not closed.
+// If encoding or writing fails, the pipeline is canceled with the error.
+// The returned channel will close when all input values have been written and the output has been flushed, or the pipeline is canceled.
+func (input *Channel[T]) ToJSONLines(writer io.Writer, opts ...options.WriterOption) <-chan struct{} {
+	buffered := bufio.NewWriter(writer)
+	encoder := json.NewEncoder(buffered)
+	write := func(value T) error {
+		return encoder.Encode(value)
+	}
+
+	return toWriter("ToJSONLines", input, nil, write, buffered.Flush, getFlushEvery(opts), getNodeOptions(opts))
+}
+
+// ToCSV writes every record coming from the input channel to the writer in CSV format. Every record is a slice with its fields.
+// The delimiter can be customized with the CSV option.
+// Output is buffered, and it can be flushed periodically with the FlushEvery option. The writer is not closed.
+// If writing fails, the pipeline is canceled with the error.
+// The returned channel will close when all input values have been written and the output has been flushed, or the pipeline is canceled.
+func ToCSV(input *Channel[[]string], writer io.Writer, opts ...options.CSVWriterOption) <-chan struct{} {
+	csvWriter := newCSVWriter(writer, opts)
+	flush := func() error {
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+
+	return toWriter("ToCSV", input, nil, csvWriter.Write, flush, getFlushEvery(opts), getNodeOptions(opts))
+}
+
+// ToCSVRecords writes every struct coming from the input channel to the writer in CSV format, with a column for every field.
+// Columns are named and struct fields are formatted as described for FromCSVRecords, so the output can be read back with it.
+// A header with the column names is written first if the CSV option sets a header, even if there are no input values.
+// Output is buffered, and it can be flushed periodically with the FlushEvery option. The writer is not closed.
+// If writing fails, or T is not a struct, the pipeline is canceled with the error.
+// The returned channel will close when all input values have been written and the output has been flushed, or the pipeline is canceled.
+//
+// Example:
+//
+//  <-users.ToCSVRecords(file, CSV().WithHeader(), FlushEvery(100, time.Second))
+func (input *Channel[T]) ToCSVRecords(writer io.Writer, opts ...options.CSVWriterOption) <-chan struct{} {
+	csvOpt := getOptionOrDefault(opts, CSV())
+	csvWriter := newCSVWriter(writer, opts)
+	var mapper *csvMapper[T]
+	start := func() error {
+		var err error
+		if mapper, err = newCSVMapper[T](nil, canFormatCSVField); err != nil {
+			return err
+		}
+		if csvOpt.Header {
+			return csvWriter.Write(mapper.names)
+		}
+		return nil
+	}
+	write := func(value T) error {
+		record, err := mapper.record(value)
+		if err != nil {
+			return err
+		}
+		return csvWriter.Write(record)
+	}
+	flush := func() error {
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+
+	return toWriter("ToCSVRecords", input, start, write, flush, getFlushEvery(opts), getNodeOptions(opts))
+}
+
+func newCSVWriter[O any](writer io.Writer, opts []O) *csv.Writer {
+	csvOpt := getOptionOrDefault(opts, CSV())
+	csvWriter := csv.NewWriter(writer)
+	if csvOpt.Comma != 0 {
+		csvWriter.Comma = csvOpt.Comma
+	}
+	return csvWriter
+}
+
+func getFlushEvery[O any](opts []O) options.FlushEvery {
+	return getOptionOrDefault(opts, FlushEvery(0, 0))
+}
+
+// toWriter creates a sink calling write for every input value, and flush according to flushEvery and when exiting.
+// start, if not nil, is called before reading any value. Any error cancels the pipeline.
+func toWriter[T any](nodeType string, input *Channel[T], start func() error, write func(T) error, flush func() error, flushEvery options.FlushEvery, opts []options.NodeOption) <-chan struct{} {
+	worker := func(node workerNode[T, any]) {
+		if start != nil {
+			if err := start(); err != nil {
+				node.Cancel(err)
+				return
+			}
+		}
+
+		var lock sync.Mutex // the periodic flush runs concurrently with writes
+		pending := 0
+		flushPending := func() error { // must be called with the lock held
+			pending = 0
+			return flush()
+		}
+
+		stopTicker := func() {}
+		if flushEvery.Interval > 0 {
+			exited := make(chan struct{})
+			tickerDone := make(chan struct{})
+			go func() {
+				defer close(tickerDone)
+				ticker := time.NewTicker(flushEvery.Interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-exited:
+						return
+					case <-ticker.C:
+					}
+					lock.Lock()
+					var err error
+					if pending > 0 {
+						err = flushPending()
+					}
+					lock.Unlock()
+					if err != nil {
+						node.Cancel(err)
+						return
+					}
+				}
+			}()
+			stopTicker = func() {
+				close(exited)
+				<-tickerDone
+			}
+		}
+
+		node.LoopInput(0, func(value T) bool {
+			lock.Lock()
+			err := write(value)
+			pending++
+			if err == nil && flushEvery.Count > 0 && pending >= flushEvery.Count {
+				err = flushPending()
+			}
+			lock.Unlock()
+			if err != nil {
+				node.Cancel(err)
+				return false
+			}
+			return true
+		})
+
+		stopTicker()
+		if err := flush(); err != nil { // the final flush is done even if the pipeline was canceled, so written values are not lost
+			node.Cancel(err)
+		}
+	}
+
+	node := newSinkPipelineNode(nodeType, input, worker, opts...)
+	return node.Done()
+}
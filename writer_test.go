@@ -0,0 +1,200 @@
+package jpipe_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junitechnology/jpipe"
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a bytes.Buffer that can be read while a sink writes to it.
+// If writes is not nil, every write is also sent to it, so tests can wait for them.
+type syncBuffer struct {
+	lock   sync.Mutex
+	buffer bytes.Buffer
+	writes chan string
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.writes != nil {
+		b.writes <- string(p)
+	}
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buffer.String()
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestToWriter(t *testing.T) {
+	t.Run("Writes every value as a line", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		buffer := &syncBuffer{}
+
+		<-jpipe.FromSlice(pipeline, []int{1, 2, 3}).ToWriter(buffer)
+
+		assert.Equal(t, "1\n2\n3\n", buffer.String())
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Flushes every count values", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		buffer := &syncBuffer{writes: make(chan string, 10)}
+		goChannel := make(chan int)
+		done := jpipe.FromGoChannel(pipeline, goChannel).ToWriter(buffer, jpipe.FlushEvery(2, 0))
+
+		goChannel <- 1
+		goChannel <- 2
+		assert.Equal(t, "1\n2\n", <-buffer.writes) // both values are written at once
+		goChannel <- 3
+		close(goChannel)
+		<-done
+
+		assert.Equal(t, "3\n", <-buffer.writes)
+		assert.Equal(t, "1\n2\n3\n", buffer.String())
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Flushes every interval", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		buffer := &syncBuffer{writes: make(chan string, 10)}
+		goChannel := make(chan int)
+		jpipe.FromGoChannel(pipeline, goChannel).ToWriter(buffer, jpipe.FlushEvery(0, 20*time.Millisecond))
+
+		goChannel <- 1
+
+		select { // flushed with no more values, and the pipeline still running
+		case written := <-buffer.writes:
+			assert.Equal(t, "1\n", written)
+		case <-time.After(time.Second):
+			assert.Fail(t, "value must be flushed after the interval")
+		}
+		assert.False(t, pipeline.IsDone())
+		cancelPipeline(pipeline)
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+	})
+
+	t.Run("Flushes written values if pipeline canceled", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		buffer := &syncBuffer{}
+		goChannel := make(chan int)
+		done := jpipe.FromGoChannel(pipeline, goChannel).ToWriter(buffer)
+
+		goChannel <- 1
+		goChannel <- 2
+		time.Sleep(time.Millisecond) // give time for the last value to be written
+		cancelPipeline(pipeline)
+
+		assertChannelClosed(t, done, 10*time.Millisecond)
+		assert.Equal(t, "1\n2\n", buffer.String())
+	})
+
+	t.Run("Cancels pipeline on write error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		errTest := errors.New("test error")
+
+		<-jpipe.FromSlice(pipeline, []int{1, 2, 3}).ToWriter(failingWriter{err: errTest}, jpipe.FlushEvery(1, 0))
+
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+
+	t.Run("Cancels pipeline on final flush error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		errTest := errors.New("test error")
+
+		<-jpipe.FromSlice(pipeline, []int{1, 2, 3}).ToWriter(failingWriter{err: errTest})
+
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorIs(t, pipeline.Error(), errTest)
+	})
+}
+
+func TestToJSONLines(t *testing.T) {
+	t.Run("Writes every value as a JSON line", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		buffer := &syncBuffer{}
+
+		<-jpipe.FromSlice(pipeline, []jsonUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}).ToJSONLines(buffer)
+
+		assert.Equal(t, "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n", buffer.String())
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Cancels pipeline on encode error", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+
+		<-jpipe.FromSlice(pipeline, []any{1, func() {}}).ToJSONLines(&syncBuffer{})
+
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.ErrorContains(t, pipeline.Error(), "unsupported type")
+	})
+}
+
+func TestToCSV(t *testing.T) {
+	t.Run("Writes every record as a CSV row", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		buffer := &syncBuffer{}
+
+		<-jpipe.ToCSV(jpipe.FromSlice(pipeline, [][]string{{"a", "b"}, {"c;d", "e"}}), buffer, jpipe.CSV().WithComma(';'))
+
+		assert.Equal(t, "a;b\n\"c;d\";e\n", buffer.String())
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+}
+
+func TestToCSVRecords(t *testing.T) {
+	t.Run("Writes every struct as a CSV row with a header", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		buffer := &syncBuffer{}
+		users := []csvUser{{ID: 1, Name: "a", Active: true, Timeout: time.Second, Ignored: "x"}, {ID: 2, Name: "b"}}
+
+		<-jpipe.FromSlice(pipeline, users).ToCSVRecords(buffer, jpipe.CSV().WithHeader())
+
+		assert.Equal(t, "id,name,active,timeout\n1,a,true,1s\n2,b,false,0s\n", buffer.String())
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.NoError(t, pipeline.Error())
+	})
+
+	t.Run("Output can be read back", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+		buffer := &syncBuffer{}
+		users := []csvUser{{ID: 1, Name: "a, b", Active: true, Timeout: time.Second}, {ID: 2, Name: "c"}}
+
+		<-jpipe.FromSlice(pipeline, users).ToCSVRecords(buffer)
+		readPipeline := jpipe.New(context.TODO())
+		values := drainChannel(jpipe.FromCSVRecords[csvUser](readPipeline, strings.NewReader(buffer.String())))
+
+		assert.Equal(t, users, values)
+	})
+
+	t.Run("Cancels pipeline if type is not a struct", func(t *testing.T) {
+		pipeline := jpipe.New(context.TODO())
+
+		<-jpipe.FromSlice(pipeline, []int{1}).ToCSVRecords(&syncBuffer{})
+
+		assertPipelineDone(t, pipeline, 10*time.Millisecond)
+		assert.Error(t, pipeline.Error())
+	})
+}